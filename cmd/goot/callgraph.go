@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// runCallGraph prints the call edges from the functions of the packages
+func runCallGraph(args []string) int {
+	o := new(options)
+	fs := newFlagSet("callgraph", o)
+	fs.StringVar(&o.Output, "o", "", "write to this file instead of stdout")
+	algorithm := fs.String("callgraph", "cha", "call graph algorithm: static, cha, vta or rta")
+	format := fs.String("format", "text", "output format: text prints a caller and a callee a line, dot prints a digraph")
+	if !o.parse(fs, args) {
+		return exitError
+	}
+	if *format != "text" && *format != "dot" {
+		return fail(fmt.Errorf("unknown format %q", *format))
+	}
+	a, err := icfg.ParseAlgorithm(*algorithm)
+	if err != nil {
+		return fail(err)
+	}
+	prog, pkgs, err := o.load()
+	if err != nil {
+		return fail(err)
+	}
+	g, err := icfg.Build(prog, a)
+	if err != nil {
+		return fail(err)
+	}
+	w, closeOutput, err := o.output()
+	if err != nil {
+		return fail(err)
+	}
+
+	edges := make([]*callgraph.Edge, 0)
+	for _, f := range o.functions(prog, pkgs) {
+		if node := g.CallGraph.Nodes[f]; node != nil {
+			edges = append(edges, node.Out...)
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].Caller.Func != edges[j].Caller.Func {
+			return edges[i].Caller.Func.String() < edges[j].Caller.Func.String()
+		}
+		return edges[i].Callee.Func.String() < edges[j].Callee.Func.String()
+	})
+	if *format == "dot" {
+		fmt.Fprintln(w, "digraph callgraph {")
+	}
+	seen := make(map[[2]*ssa.Function]bool)
+	for _, e := range edges {
+		key := [2]*ssa.Function{e.Caller.Func, e.Callee.Func}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if *format == "dot" {
+			fmt.Fprintf(w, "\t%s -> %s;\n", strconv.Quote(e.Caller.Func.String()), strconv.Quote(e.Callee.Func.String()))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\n", e.Caller.Func, e.Callee.Func)
+		}
+	}
+	if *format == "dot" {
+		fmt.Fprintln(w, "}")
+	}
+	if err := closeOutput(); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
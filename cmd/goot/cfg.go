@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// runCFG prints the control flow graphs of the functions
+func runCFG(args []string) int {
+	o := new(options)
+	fs := newFlagSet("cfg", o)
+	fs.StringVar(&o.Func, "func", "", "only print the function of this name")
+	fs.StringVar(&o.Output, "o", "", "write to this file instead of stdout")
+	format := fs.String("format", "text", "output format: text prints the SSA, dot prints the UnitGraphs")
+	exceptional := fs.Bool("exceptional", false, "add panic edges and deferred calls to the UnitGraphs")
+	if !o.parse(fs, args) {
+		return exitError
+	}
+	if *format != "text" && *format != "dot" {
+		return fail(fmt.Errorf("unknown format %q", *format))
+	}
+	prog, pkgs, err := o.load()
+	if err != nil {
+		return fail(err)
+	}
+	w, closeOutput, err := o.output()
+	if err != nil {
+		return fail(err)
+	}
+	for _, f := range o.functions(prog, pkgs) {
+		if *format == "text" {
+			f.WriteTo(w)
+			fmt.Fprintln(w)
+			continue
+		}
+		writeDot(w, graph.NewWithOptions(f, graph.Options{Exceptional: *exceptional}))
+	}
+	if err := closeOutput(); err != nil {
+		return fail(err)
+	}
+	return exitOK
+}
+
+// writeDot writes a UnitGraph in dot, the units are numbered by their order in the UnitChain
+func writeDot(w io.Writer, g *graph.UnitGraph) {
+	id := make(map[ssa.Instruction]int, len(g.UnitChain))
+	for i, u := range g.UnitChain {
+		id[u] = i
+	}
+	fmt.Fprintf(w, "digraph %s {\n", strconv.Quote(g.Func.String()))
+	for i, u := range g.UnitChain {
+		fmt.Fprintf(w, "\tn%d [label=%s];\n", i, strconv.Quote(unitLabel(u)))
+	}
+	for i, u := range g.UnitChain {
+		for _, succ := range g.GetSuccs(u) {
+			fmt.Fprintf(w, "\tn%d -> n%d;\n", i, id[succ])
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// unitLabel returns the text of a unit, with the value it defines
+func unitLabel(u ssa.Instruction) string {
+	if v, ok := u.(ssa.Value); ok && v.Name() != "" {
+		return v.Name() + " = " + u.String()
+	}
+	return u.String()
+}
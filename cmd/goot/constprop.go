@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"github.com/zeroy0410/goot/pkg/example/dataflow/constantpropagation"
+)
+
+// runConstProp runs constant propagation on the functions and reports constant conditions
+func runConstProp(args []string) int {
+	o := new(options)
+	fs := newFlagSet("constprop", o)
+	fs.StringVar(&o.Func, "func", "", "only analyze the function of this name")
+	if !o.parse(fs, args) {
+		return exitError
+	}
+	prog, pkgs, err := o.load()
+	if err != nil {
+		return fail(err)
+	}
+	findings := 0
+	for _, f := range o.functions(prog, pkgs) {
+		if o.Debug {
+			// 调试模式下输出函数的 SSA 和每条指令上的常量
+			solver.Solve(constantpropagation.New(graph.New(f)), true)
+		}
+		for _, c := range constantpropagation.FindConstantConditions(f) {
+			fmt.Printf("%v: condition is always %v\n", prog.Fset.Position(c.Pos()), c.Value)
+			findings++
+		}
+	}
+	if findings != 0 {
+		return exitFindings
+	}
+	return exitOK
+}
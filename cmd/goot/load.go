@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+const (
+	exitOK       = 0 // no findings
+	exitFindings = 1 // the analysis reported findings
+	exitError    = 2 // bad usage or failure of loading or analysis
+)
+
+// options represents the flags shared by subcommands
+type options struct {
+	Dir      string
+	Debug    bool
+	Func     string
+	Output   string
+	patterns []string
+}
+
+// newFlagSet returns a flag set of a subcommand with the shared flags registered to o
+func newFlagSet(name string, o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet("goot "+name, flag.ContinueOnError)
+	fs.StringVar(&o.Dir, "dir", "", "directory the package patterns are loaded in, default is the current directory")
+	fs.BoolVar(&o.Debug, "debug", false, "print debug information of the analysis")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: goot %s [flags] [packages]\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args, the rest arguments are package patterns, "./..." if there is none
+func (o *options) parse(fs *flag.FlagSet, args []string) bool {
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	o.patterns = fs.Args()
+	if len(o.patterns) == 0 {
+		o.patterns = []string{"./..."}
+	}
+	return true
+}
+
+// load loads the packages of the patterns and builds the SSA of them
+// dependencies are created without bodies, so only functions of the packages are analyzed
+func (o *options) load() (*ssa.Program, []*ssa.Package, error) {
+	mode := packages.NeedName |
+		packages.NeedFiles |
+		packages.NeedCompiledGoFiles |
+		packages.NeedSyntax |
+		packages.NeedTypesInfo |
+		packages.NeedImports |
+		packages.NeedTypesSizes |
+		packages.NeedTypes |
+		packages.NeedDeps
+	cfg := &packages.Config{Mode: mode, Dir: o.Dir}
+	initial, err := packages.Load(cfg, o.patterns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if packages.PrintErrors(initial) > 0 {
+		return nil, nil, errors.New("packages contain errors")
+	}
+	if len(initial) == 0 {
+		return nil, nil, fmt.Errorf("no packages match %v", o.patterns)
+	}
+	prog, pkgs := ssautil.Packages(initial, 0)
+	built := make([]*ssa.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg != nil {
+			pkg.Build()
+			built = append(built, pkg)
+		}
+	}
+	return prog, built, nil
+}
+
+// functions returns the functions with bodies of pkgs sorted by name, filtered by the -func flag
+func (o *options) functions(prog *ssa.Program, pkgs []*ssa.Package) []*ssa.Function {
+	in := make(map[*ssa.Package]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		in[pkg] = true
+	}
+	funcs := make([]*ssa.Function, 0)
+	for f := range ssautil.AllFunctions(prog) {
+		if f.Blocks == nil || !in[f.Pkg] {
+			continue
+		}
+		if o.Func != "" && f.String() != o.Func && f.Name() != o.Func {
+			continue
+		}
+		funcs = append(funcs, f)
+	}
+	sort.Slice(funcs, func(i, j int) bool {
+		return funcs[i].String() < funcs[j].String()
+	})
+	return funcs
+}
+
+// output opens the -o file, or returns stdout if it is not set
+func (o *options) output() (io.Writer, func() error, error) {
+	if o.Output == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(o.Output)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// fail prints err and returns exitError
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "goot:", err)
+	return exitError
+}
@@ -0,0 +1,62 @@
+// Command goot runs the analyses of goot on Go packages
+//
+//	goot <command> [flags] [packages]
+//
+// it exits with 1 when an analysis reports findings, and with 2 on errors
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command represents a subcommand of goot
+type command struct {
+	Name  string
+	Short string
+	Run   func(args []string) int
+}
+
+var commands = []*command{
+	{Name: "taint", Short: "report taint flowing to sinks", Run: runTaint},
+	{Name: "constprop", Short: "report conditions which are always true or always false", Run: runConstProp},
+	{Name: "typeassert", Short: "print the types flowing to type assertions", Run: runTypeAssert},
+	{Name: "cfg", Short: "print control flow graphs of functions", Run: runCFG},
+	{Name: "callgraph", Short: "print the call graph of packages", Run: runCallGraph},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches args to a subcommand and returns the exit code
+func run(args []string) int {
+	if len(args) == 0 {
+		usage()
+		return exitError
+	}
+	for _, c := range commands {
+		if c.Name == args[0] {
+			return c.Run(args[1:])
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage()
+		return exitOK
+	}
+	fmt.Fprintf(os.Stderr, "goot: unknown command %q\n", args[0])
+	usage()
+	return exitError
+}
+
+// usage prints the subcommands
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: goot <command> [flags] [packages]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", c.Name, c.Short)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "run 'goot <command> -h' for the flags of a command")
+}
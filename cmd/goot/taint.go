@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"github.com/zeroy0410/goot/pkg/example/dataflow/taint"
+)
+
+// runTaint runs taint analysis on the packages and reports every path from a source to a sink
+func runTaint(args []string) int {
+	o := new(options)
+	fs := newFlagSet("taint", o)
+	r := taint.NewRunner()
+	algorithm := fs.String("callgraph", "cha", "call graph algorithm: cha resolves calls by the interface hierarchy, static, vta and rta use the call graph of the algorithm")
+	fs.StringVar(&r.ModuleName, "module", "", "module name, functions of it are intra nodes")
+	rules := fs.String("rules", "", "comma separated yaml or json rule packs of sources and sinks, empty means the built-in rules")
+	fs.StringVar(&r.PassThroughDstPath, "passthrough", "", "write passthroughs to this json file")
+	fs.StringVar(&r.TaintGraphDstPath, "taintgraph", "", "write taint edges to this json file")
+	fs.StringVar(&r.FindingsDstPath, "findings", "", "write paths from sources to sinks to this json file")
+	fs.StringVar(&r.SARIFDstPath, "sarif", "", "write findings to this SARIF 2.1.0 file")
+	fs.StringVar(&r.TargetFunc, "target", "", "only analyze this function and print its SSA")
+	fs.BoolVar(&r.PassBack, "passback", false, "pass taint of parameters back to the arguments of callers")
+	fs.BoolVar(&r.Exceptional, "exceptional", false, "add panic edges and run deferred calls at function exits")
+	fs.IntVar(&r.FieldDepth, "field-depth", 0, "k-limit of access paths tracking taint of fields, 0 means a tainted field taints the whole value")
+	fs.BoolVar(&r.UseAliasAnalysis, "alias", false, "pass taint stored or loaded through a pointer to the other pointers to the same memory")
+	fs.IntVar(&r.Workers, "workers", 1, "number of goroutines analyzing functions")
+	fs.StringVar(&r.CacheDir, "cache", "", "directory caching summaries between runs")
+	fs.IntVar(&r.MaxComputations, "max-computations", taint.DefaultMaxComputations, "computation limit of a function")
+	fs.DurationVar(&r.FunctionTimeout, "function-timeout", 0, "time limit of a function, 0 means no limit")
+	fs.Uint64Var(&r.FunctionMaxMemory, "function-max-memory", 0, "heap limit in bytes while solving a function, 0 means no limit")
+	fs.DurationVar(&r.Timeout, "timeout", 0, "time limit of the whole analysis, 0 means no limit")
+	fs.Uint64Var(&r.MaxMemory, "max-memory", 0, "heap limit in bytes of the whole analysis, 0 means no limit")
+	if !o.parse(fs, args) {
+		return exitError
+	}
+	if *algorithm != "cha" {
+		a, err := icfg.ParseAlgorithm(*algorithm)
+		if err != nil {
+			return fail(err)
+		}
+		r.UsePointerAnalysis = true
+		r.CallGraphAlgorithm = a
+	}
+	if *rules != "" {
+		r.RuleFiles = strings.Split(*rules, ",")
+	}
+	r.PkgPath = o.patterns
+	r.Dir = o.Dir
+	r.Debug = o.Debug
+
+	err := r.Run()
+	if err != nil && r.Graph == nil {
+		return fail(err)
+	}
+	// a run cut short by -timeout still reports what it found, and which functions are unsound
+	for _, n := range r.NotConverged {
+		fmt.Printf("warning: %s not converged because of %v after %d computations\n", n.Function, n.Reason, n.Computations)
+	}
+
+	if r.Debug {
+		// edges into sinks, whether a source reaches them or not
+		edges := *r.Graph.Edges
+		keys := make([]string, 0, len(edges))
+		for key, edge := range edges {
+			if edge.ToIsSink {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			edge := edges[key]
+			fmt.Printf("%v: taint: position %d of %s reaches position %d of sink %s\n", edge.Position, edge.FromIndex, edge.From, edge.ToIndex, edge.To)
+		}
+	}
+	for _, finding := range r.Findings {
+		fmt.Printf("finding: position %d of source %s reaches position %d of sink %s\n", finding.SourceIndex, finding.Source, finding.SinkIndex, finding.Sink)
+		for _, edge := range finding.Path {
+			fmt.Printf("    %v: %s (position %d of %s to position %d of %s)\n", edge.Position, edge.Instruction, edge.FromIndex, edge.From, edge.ToIndex, edge.To)
+		}
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if len(r.Findings) != 0 {
+		return exitFindings
+	}
+	return exitOK
+}
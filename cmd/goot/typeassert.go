@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"github.com/zeroy0410/goot/pkg/example/dataflow/typeassertion"
+)
+
+// runTypeAssert runs type assertion analysis on the functions and prints its flows
+func runTypeAssert(args []string) int {
+	o := new(options)
+	fs := newFlagSet("typeassert", o)
+	fs.StringVar(&o.Func, "func", "", "only analyze the function of this name")
+	if !o.parse(fs, args) {
+		return exitError
+	}
+	prog, pkgs, err := o.load()
+	if err != nil {
+		return fail(err)
+	}
+	for _, f := range o.functions(prog, pkgs) {
+		solver.Solve(typeassertion.New(graph.New(f)), o.Debug)
+	}
+	return exitOK
+}
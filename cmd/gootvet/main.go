@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/zeroy0410/goot/pkg/example/dataflow/constantpropagation"
+	"github.com/zeroy0410/goot/pkg/example/dataflow/taint"
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() {
+	multichecker.Main(taint.Analyzer, constantpropagation.Analyzer)
+}
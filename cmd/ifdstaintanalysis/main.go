@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/zeroy0410/goot/pkg/example/dataflow/ifdstaint"
+)
+
+const src = `package main
+
+func source() string {
+	return "secret"
+}
+
+func sink(s string) {
+}
+
+func id(s string) string {
+	return s
+}
+
+func wrap(s string) string {
+	return "<" + id(s) + ">"
+}
+
+func clean(s string) string {
+	return "clean"
+}
+
+func main() {
+	a := source()
+	b := wrap(a)
+	c := clean(a)
+	sink(b)
+	sink(c)
+	sink(id("constant"))
+}`
+
+func main() {
+	runner := ifdstaint.NewRunner(src, "main")
+	runner.Run()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/zeroy0410/goot/pkg/example/dataflow/interval"
+)
+
+const src = `package main
+
+func Hello(n int) int {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		sum = sum + i
+	}
+	x := n
+	if x > 100 {
+		x = 100
+	}
+	if x < 0 {
+		x = 0
+	}
+	return sum + x
+}`
+
+func main() {
+	runner := interval.NewRunner(src, "Hello")
+	runner.Run()
+}
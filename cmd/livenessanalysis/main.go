@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/zeroy0410/goot/pkg/example/dataflow/liveness"
+)
+
+const src = `package main
+
+func Hello(a int, b int) int {
+	x := a + 1
+	y := b * 2
+	for {
+		if x > 10 {
+			return y
+		}
+		x = x + y
+	}
+}`
+
+func main() {
+	runner := liveness.NewRunner(src, "Hello")
+	runner.Run()
+}
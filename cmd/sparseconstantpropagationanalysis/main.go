@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/zeroy0410/goot/pkg/example/dataflow/constantpropagation"
+)
+
+const src = `package main
+
+type Point struct {
+	x int
+	y int
+}
+
+func Hello(n int) int {
+	var p Point
+	p.x = 1
+	p.y = p.x + 1
+	a := p.x * p.y
+	b := a
+	for i := 0; i < n; i++ {
+		b = a + i
+	}
+	if n > 0 {
+		p.x = 3
+	}
+	return a + b + p.x
+}`
+
+func main() {
+	runner := constantpropagation.NewRunner(src, "Hello")
+	runner.Sparse = true
+	runner.Run()
+}
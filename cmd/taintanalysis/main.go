@@ -9,6 +9,7 @@ func main() {
 	// the ../../ takes you back to root of the project
 	// and the ... means scan packages in package pkg recursively
 	runner := taint.NewRunner("C:/Users/zeroy/Documents/Code/goot/cmd/taintanalysis/nilaway/...")
+	runner.Dir = "C:/Users/zeroy/Documents/Code/goot/cmd/taintanalysis/nilaway/"
 	// the module name is the name defined in go.mod
 	runner.ModuleName = "go.uber.org/nilaway"
 	//runner.PassThroughSrcPath = []string{"gostd1.19.json", "additional.json"}
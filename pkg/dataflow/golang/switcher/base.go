@@ -1,6 +1,7 @@
 package switcher
 
 import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -115,3 +116,6 @@ func (s *BaseSwitcher) CaseMapUpdate(inst *ssa.MapUpdate) {}
 
 // CaseDebugRef accepts a DebugRef instruction
 func (s *BaseSwitcher) CaseDebugRef(inst *ssa.DebugRef) {}
+
+// CaseDeferredCall accepts a deferred call of an exceptional UnitGraph
+func (s *BaseSwitcher) CaseDeferredCall(inst *graph.DeferredCall) {}
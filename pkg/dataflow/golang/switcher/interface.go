@@ -1,6 +1,9 @@
 package switcher
 
-import "golang.org/x/tools/go/ssa"
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"golang.org/x/tools/go/ssa"
+)
 
 // Switcher represents a ssa instruction switcher
 type Switcher interface {
@@ -40,6 +43,7 @@ type Switcher interface {
 	CaseStore(inst *ssa.Store)
 	CaseMapUpdate(inst *ssa.MapUpdate)
 	CaseDebugRef(inst *ssa.DebugRef)
+	CaseDeferredCall(inst *graph.DeferredCall)
 }
 
 // Apply call specific method based on type of the instruction
@@ -109,6 +113,8 @@ func Apply(s Switcher, _inst ssa.Instruction) {
 		s.CaseGo(inst)
 	case *ssa.Defer:
 		s.CaseDefer(inst)
+	case *graph.DeferredCall:
+		s.CaseDeferredCall(inst)
 	case *ssa.Send:
 		s.CaseSend(inst)
 	case *ssa.Store:
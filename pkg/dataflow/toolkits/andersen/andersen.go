@@ -0,0 +1,422 @@
+package andersen
+
+import (
+	"go/token"
+	"go/types"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// node represents a set of objects in the constraint graph, a value, a component of a tuple,
+// a result of a function or the content of an object
+type node struct {
+	pts     map[*Object]bool
+	pending []*Object      // objects added since the node was last visited
+	succs   map[int]bool   // nodes whose sets include the set of the node
+	loads   []int          // nodes loading from the objects of the node
+	stores  []int          // nodes stored to the objects of the node
+	fields  []fieldAddress // nodes pointing to fields of the objects of the node
+}
+
+// fieldAddress represents a node pointing to a field of the objects of another node
+type fieldAddress struct {
+	dst   int
+	field string
+}
+
+// tupleKey represents a component of a tuple value
+type tupleKey struct {
+	v     ssa.Value
+	index int
+}
+
+// resultKey represents a result of a function
+type resultKey struct {
+	f     *ssa.Function
+	index int
+}
+
+// analysis represents an Andersen analysis in progress
+type analysis struct {
+	nodes    []*node
+	values   map[ssa.Value]int
+	tuples   map[tupleKey]int
+	results  map[resultKey]int
+	objects  map[ssa.Value]*Object
+	worklist []int
+	queued   map[int]bool
+}
+
+// Analyze runs an inclusion based, flow and context insensitive points-to analysis on funcs
+// calls without a static callee are resolved by cg, which may be nil, and functions without a body
+// return nothing, objects are allocation sites and their fields, the elements of arrays and slices are not told apart
+func Analyze(funcs map[*ssa.Function]bool, cg *callgraph.Graph) *Result {
+	a := new(analysis)
+	a.values = make(map[ssa.Value]int)
+	a.tuples = make(map[tupleKey]int)
+	a.results = make(map[resultKey]int)
+	a.objects = make(map[ssa.Value]*Object)
+	a.queued = make(map[int]bool)
+
+	sorted := make([]*ssa.Function, 0, len(funcs))
+	for f := range funcs {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].String() < sorted[j].String() })
+	for _, f := range sorted {
+		a.generate(f, cg)
+	}
+	a.solve()
+
+	r := new(Result)
+	r.analysis = a
+	r.index = make(map[*ssa.Function]*funcIndex)
+	r.mu = new(sync.Mutex)
+	return r
+}
+
+// newNode adds a node and returns its id
+func (a *analysis) newNode() int {
+	a.nodes = append(a.nodes, &node{pts: make(map[*Object]bool), succs: make(map[int]bool)})
+	return len(a.nodes) - 1
+}
+
+// valueNode returns the node of v, a global points to its own object
+func (a *analysis) valueNode(v ssa.Value) int {
+	if id, ok := a.values[v]; ok {
+		return id
+	}
+	id := a.newNode()
+	a.values[v] = id
+	if g, ok := v.(*ssa.Global); ok {
+		a.addObject(id, a.siteObject(g))
+	}
+	return id
+}
+
+// tupleNode returns the node of the index'th component of the tuple v
+func (a *analysis) tupleNode(v ssa.Value, index int) int {
+	key := tupleKey{v: v, index: index}
+	if id, ok := a.tuples[key]; ok {
+		return id
+	}
+	id := a.newNode()
+	a.tuples[key] = id
+	return id
+}
+
+// resultNode returns the node of the index'th result of f
+func (a *analysis) resultNode(f *ssa.Function, index int) int {
+	key := resultKey{f: f, index: index}
+	if id, ok := a.results[key]; ok {
+		return id
+	}
+	id := a.newNode()
+	a.results[key] = id
+	return id
+}
+
+// newObject returns an object with its content and whole nodes
+func (a *analysis) newObject(site ssa.Value, root *Object, path string, depth int) *Object {
+	o := &Object{site: site, root: root, path: path, depth: depth, children: make(map[string]*Object)}
+	if o.root == nil {
+		o.root = o
+	}
+	o.content = a.newNode()
+	o.whole = a.newNode()
+	a.addEdge(o.content, o.whole)
+	return o
+}
+
+// siteObject returns the object allocated at site
+func (a *analysis) siteObject(site ssa.Value) *Object {
+	if o, ok := a.objects[site]; ok {
+		return o
+	}
+	o := a.newObject(site, nil, "", 0)
+	a.objects[site] = o
+	return o
+}
+
+// fieldObject returns the object of field of o, a store to o is a store to its fields,
+// and a load from o loads its fields too
+func (a *analysis) fieldObject(o *Object, field string) *Object {
+	if o.depth >= maxDepth {
+		return o
+	}
+	if child, ok := o.children[field]; ok {
+		return child
+	}
+	path := field
+	if o.path != "" {
+		path = o.path + "." + field
+	}
+	child := a.newObject(o.site, o.root, path, o.depth+1)
+	o.children[field] = child
+	a.addEdge(o.content, child.content)
+	a.addEdge(child.whole, o.whole)
+	return child
+}
+
+// addObject adds o to the set of a node
+func (a *analysis) addObject(id int, o *Object) {
+	n := a.nodes[id]
+	if n.pts[o] {
+		return
+	}
+	n.pts[o] = true
+	n.pending = append(n.pending, o)
+	if !a.queued[id] {
+		a.queued[id] = true
+		a.worklist = append(a.worklist, id)
+	}
+}
+
+// addEdge makes the set of dst include the set of src
+func (a *analysis) addEdge(src int, dst int) {
+	if src == dst || a.nodes[src].succs[dst] {
+		return
+	}
+	a.nodes[src].succs[dst] = true
+	for o := range a.nodes[src].pts {
+		a.addObject(dst, o)
+	}
+}
+
+// addLoad makes dst include the values loaded from the objects of src
+func (a *analysis) addLoad(src int, dst int) {
+	a.nodes[src].loads = append(a.nodes[src].loads, dst)
+	for o := range a.nodes[src].pts {
+		a.addEdge(o.whole, dst)
+	}
+}
+
+// addStore makes the objects of dst include the values of src
+func (a *analysis) addStore(dst int, src int) {
+	a.nodes[dst].stores = append(a.nodes[dst].stores, src)
+	for o := range a.nodes[dst].pts {
+		a.addEdge(src, o.content)
+	}
+}
+
+// addField makes dst point to field of the objects of src
+func (a *analysis) addField(src int, dst int, field string) {
+	a.nodes[src].fields = append(a.nodes[src].fields, fieldAddress{dst: dst, field: field})
+	objects := make([]*Object, 0, len(a.nodes[src].pts))
+	for o := range a.nodes[src].pts {
+		objects = append(objects, o)
+	}
+	for _, o := range objects {
+		a.addObject(dst, a.fieldObject(o, field))
+	}
+}
+
+// solve propagates objects along the constraints until no set changes
+func (a *analysis) solve() {
+	for len(a.worklist) != 0 {
+		id := a.worklist[0]
+		a.worklist = a.worklist[1:]
+		a.queued[id] = false
+		n := a.nodes[id]
+		pending := n.pending
+		n.pending = nil
+		for _, o := range pending {
+			for succ := range n.succs {
+				a.addObject(succ, o)
+			}
+			for _, dst := range n.loads {
+				a.addEdge(o.whole, dst)
+			}
+			for _, src := range n.stores {
+				a.addEdge(src, o.content)
+			}
+			for _, field := range n.fields {
+				a.addObject(field.dst, a.fieldObject(o, field.field))
+			}
+		}
+	}
+}
+
+// generate adds the constraints of the instructions of f, free vars are bound at MakeClosure
+func (a *analysis) generate(f *ssa.Function, cg *callgraph.Graph) {
+	for _, b := range f.Blocks {
+		for _, inst := range b.Instrs {
+			a.generateInstruction(inst, cg)
+		}
+	}
+}
+
+// generateInstruction adds the constraints of an instruction
+func (a *analysis) generateInstruction(inst ssa.Instruction, cg *callgraph.Graph) {
+	switch i := inst.(type) {
+	case *ssa.Alloc:
+		a.addObject(a.valueNode(i), a.siteObject(i))
+	case *ssa.MakeSlice:
+		a.addObject(a.valueNode(i), a.siteObject(i))
+	case *ssa.MakeMap:
+		a.addObject(a.valueNode(i), a.siteObject(i))
+	case *ssa.MakeChan:
+		a.addObject(a.valueNode(i), a.siteObject(i))
+	case *ssa.MakeClosure:
+		if fn, ok := i.Fn.(*ssa.Function); ok {
+			for k, binding := range i.Bindings {
+				if k < len(fn.FreeVars) {
+					a.addEdge(a.valueNode(binding), a.valueNode(fn.FreeVars[k]))
+				}
+			}
+		}
+	case *ssa.MakeInterface:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.ChangeType:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.ChangeInterface:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.Convert:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.SliceToArrayPointer:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.Slice:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.Field:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.Index:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.IndexAddr:
+		// elements are not told apart, the address of an element points to the array
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.FieldAddr:
+		if field, ok := fieldName(i.X.Type(), i.Field); ok {
+			a.addField(a.valueNode(i.X), a.valueNode(i), field)
+		} else {
+			a.addEdge(a.valueNode(i.X), a.valueNode(i))
+		}
+	case *ssa.TypeAssert:
+		if i.CommaOk {
+			a.addEdge(a.valueNode(i.X), a.tupleNode(i, 0))
+		} else {
+			a.addEdge(a.valueNode(i.X), a.valueNode(i))
+		}
+	case *ssa.Extract:
+		a.addEdge(a.tupleNode(i.Tuple, i.Index), a.valueNode(i))
+	case *ssa.Phi:
+		for _, e := range i.Edges {
+			a.addEdge(a.valueNode(e), a.valueNode(i))
+		}
+	case *ssa.UnOp:
+		switch {
+		case i.Op == token.MUL:
+			a.addLoad(a.valueNode(i.X), a.valueNode(i))
+		case i.Op == token.ARROW && i.CommaOk:
+			a.addLoad(a.valueNode(i.X), a.tupleNode(i, 0))
+		case i.Op == token.ARROW:
+			a.addLoad(a.valueNode(i.X), a.valueNode(i))
+		}
+	case *ssa.Store:
+		a.addStore(a.valueNode(i.Addr), a.valueNode(i.Val))
+	case *ssa.MapUpdate:
+		a.addStore(a.valueNode(i.Map), a.valueNode(i.Key))
+		a.addStore(a.valueNode(i.Map), a.valueNode(i.Value))
+	case *ssa.Lookup:
+		if i.CommaOk {
+			a.addLoad(a.valueNode(i.X), a.tupleNode(i, 0))
+		} else {
+			a.addLoad(a.valueNode(i.X), a.valueNode(i))
+		}
+	case *ssa.Range:
+		a.addEdge(a.valueNode(i.X), a.valueNode(i))
+	case *ssa.Next:
+		a.addLoad(a.valueNode(i.Iter), a.tupleNode(i, 1))
+		a.addLoad(a.valueNode(i.Iter), a.tupleNode(i, 2))
+	case *ssa.Send:
+		a.addStore(a.valueNode(i.Chan), a.valueNode(i.X))
+	case *ssa.Select:
+		recv := 0
+		for _, state := range i.States {
+			if state.Dir == types.RecvOnly {
+				a.addLoad(a.valueNode(state.Chan), a.tupleNode(i, 2+recv))
+				recv++
+			} else if state.Send != nil {
+				a.addStore(a.valueNode(state.Chan), a.valueNode(state.Send))
+			}
+		}
+	case *ssa.Return:
+		f := i.Parent()
+		for k, result := range i.Results {
+			a.addEdge(a.valueNode(result), a.resultNode(f, k))
+		}
+	case ssa.CallInstruction:
+		a.generateCall(i, cg)
+	}
+}
+
+// generateCall adds the constraints of a call, its args flow to the parameters of its callees
+// and the results of its callees flow to it
+func (a *analysis) generateCall(inst ssa.CallInstruction, cg *callgraph.Graph) {
+	call := inst.Common()
+	if b, ok := call.Value.(*ssa.Builtin); ok {
+		a.generateBuiltin(b, inst)
+		return
+	}
+	callees := make([]*ssa.Function, 0)
+	if f := call.StaticCallee(); f != nil {
+		callees = append(callees, f)
+	} else if cg != nil {
+		if n := cg.Nodes[inst.Parent()]; n != nil {
+			for _, edge := range n.Out {
+				if edge.Site == inst && edge.Callee.Func != nil {
+					callees = append(callees, edge.Callee.Func)
+				}
+			}
+		}
+	}
+	args := make([]ssa.Value, 0, len(call.Args)+1)
+	if call.IsInvoke() {
+		// the receiver of an invoke is call.Value
+		args = append(args, call.Value)
+	}
+	args = append(args, call.Args...)
+	for _, f := range callees {
+		for k, arg := range args {
+			if k < len(f.Params) {
+				a.addEdge(a.valueNode(arg), a.valueNode(f.Params[k]))
+			}
+		}
+		v := inst.Value()
+		if v == nil {
+			// results of go and defer are dropped
+			continue
+		}
+		n := f.Signature.Results().Len()
+		for k := 0; k < n; k++ {
+			if n == 1 {
+				a.addEdge(a.resultNode(f, k), a.valueNode(v))
+			} else {
+				a.addEdge(a.resultNode(f, k), a.tupleNode(v, k))
+			}
+		}
+	}
+}
+
+// generateBuiltin adds the constraints of a call to a builtin
+func (a *analysis) generateBuiltin(b *ssa.Builtin, inst ssa.CallInstruction) {
+	args := inst.Common().Args
+	switch b.Name() {
+	case "append":
+		// the result may share the array of any arg
+		if v := inst.Value(); v != nil {
+			for _, arg := range args {
+				a.addEdge(a.valueNode(arg), a.valueNode(v))
+			}
+		}
+	case "copy":
+		// elements of src are stored to elements of dst
+		if len(args) == 2 {
+			tmp := a.newNode()
+			a.addLoad(a.valueNode(args[1]), tmp)
+			a.addStore(a.valueNode(args[0]), tmp)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package andersen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+const src = `package p
+
+type box struct {
+	x *int
+	y *int
+}
+
+var g *int
+
+func id(p *int) *int { return p }
+
+func f() {
+	a := new(int)
+	b := id(a)
+	c := new(int)
+	g = c
+	s := &box{}
+	px := &s.x
+	*px = a
+	d := s.x
+	e := s.y
+	_, _, _ = b, d, e
+}
+`
+
+// build returns the functions of src and f
+func build(t *testing.T) (map[*ssa.Function]bool, *ssa.Function) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := types.NewPackage("p", "p")
+	ssaPkg, _, err := ssautil.BuildPackage(&types.Config{Importer: importer.Default()}, fset, pkg, []*ast.File{file}, ssa.SanityCheckFunctions|ssa.GlobalDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ssautil.AllFunctions(ssaPkg.Prog), ssaPkg.Func("f")
+}
+
+// value returns the value of the variable name in f, the program is built with debug refs
+func value(t *testing.T, f *ssa.Function, name string) ssa.Value {
+	t.Helper()
+	for _, b := range f.Blocks {
+		for _, inst := range b.Instrs {
+			if ref, ok := inst.(*ssa.DebugRef); ok {
+				if ident, ok := ref.Expr.(*ast.Ident); ok && ident.Name == name && !ref.IsAddr {
+					return ref.X
+				}
+			}
+		}
+	}
+	t.Fatalf("no value of %s", name)
+	return nil
+}
+
+func TestPointsTo(t *testing.T) {
+	funcs, f := build(t)
+	r := Analyze(funcs, nil)
+	a, b, c, d, e := value(t, f, "a"), value(t, f, "b"), value(t, f, "c"), value(t, f, "d"), value(t, f, "e")
+
+	same := func(x ssa.Value, y ssa.Value) bool {
+		px, py := r.PointsTo(x), r.PointsTo(y)
+		return len(px) == 1 && len(py) == 1 && px[0] == py[0]
+	}
+	if !same(a, b) {
+		t.Errorf("a points to %v and b to %v, want the same object through id", r.PointsTo(a), r.PointsTo(b))
+	}
+	if same(a, c) {
+		t.Errorf("a and c point to %v, want different objects", r.PointsTo(a))
+	}
+	if !same(a, d) {
+		t.Errorf("d loaded from s.x points to %v, want the object of a stored through px", r.PointsTo(d))
+	}
+	if len(r.PointsTo(e)) != 0 {
+		t.Errorf("e loaded from s.y points to %v, want nothing", r.PointsTo(e))
+	}
+}
+
+func TestAliases(t *testing.T) {
+	funcs, f := build(t)
+	r := Analyze(funcs, nil)
+	a, b, c := value(t, f, "a"), value(t, f, "b"), value(t, f, "c")
+
+	has := func(v ssa.Value, alias ssa.Value) bool {
+		for _, other := range r.Aliases(f, v) {
+			if other.Value == alias {
+				return true
+			}
+		}
+		return false
+	}
+	if !has(a, b) || !has(b, a) {
+		t.Errorf("aliases of a are %v, want b", r.Aliases(f, a))
+	}
+	if has(a, c) {
+		t.Errorf("aliases of a are %v, want no c", r.Aliases(f, a))
+	}
+
+	// px points to field x inside the object of s
+	s, px := value(t, f, "s"), value(t, f, "px")
+	for _, alias := range r.Aliases(f, px) {
+		if alias.Value == s {
+			if alias.Path != "x" || !alias.Outer {
+				t.Errorf("s is an alias of px with path %q outer %v, want x outer", alias.Path, alias.Outer)
+			}
+			return
+		}
+	}
+	t.Errorf("aliases of px are %v, want s", r.Aliases(f, px))
+}
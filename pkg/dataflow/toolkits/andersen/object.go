@@ -0,0 +1,77 @@
+package andersen
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// maxDepth bounds the fields of an object, deeper fields are the object they are cut to
+// fields of well typed code nest as deep as their struct types, the bound only stops imprecise cycles
+const maxDepth = 8
+
+// Object represents an abstract object, the memory allocated at a site or a field of it
+type Object struct {
+	site     ssa.Value // *ssa.Alloc, *ssa.Global, *ssa.MakeSlice, *ssa.MakeMap or *ssa.MakeChan
+	root     *Object
+	path     string // access path of the field in the root object, e.g. Body.x, empty for the root
+	depth    int
+	children map[string]*Object
+	content  int // node of the values stored in the object
+	whole    int // node of the values loaded from the object, its content and the content of its fields
+}
+
+// Site returns the instruction or the global allocating the object
+func (o *Object) Site() ssa.Value {
+	return o.site
+}
+
+// Path returns the access path of the object in the memory allocated at its site, empty for the whole memory
+func (o *Object) Path() string {
+	return o.path
+}
+
+// String returns the site and the path of the object
+func (o *Object) String() string {
+	name := o.site.Name()
+	if f := o.site.Parent(); f != nil {
+		name = f.String() + "." + name
+	}
+	if o.path == "" {
+		return name
+	}
+	return name + "." + o.path
+}
+
+// relation returns the access path from o to other, and whether other is inside o
+// an empty path means they are the same object, ok is false for objects of different sites
+func (o *Object) relation(other *Object) (path string, inside bool, ok bool) {
+	if o.root != other.root {
+		return "", false, false
+	}
+	switch {
+	case o.path == other.path:
+		return "", true, true
+	case o.path == "":
+		return other.path, true, true
+	case other.path == "":
+		return o.path, false, true
+	case strings.HasPrefix(other.path, o.path+"."):
+		return strings.TrimPrefix(other.path, o.path+"."), true, true
+	case strings.HasPrefix(o.path, other.path+"."):
+		return strings.TrimPrefix(o.path, other.path+"."), false, true
+	}
+	return "", false, false
+}
+
+// fieldName returns the name of the i'th field of a struct or of a pointer to a struct
+func fieldName(typ types.Type, i int) (string, bool) {
+	if p, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+	if st, ok := typ.Underlying().(*types.Struct); ok && i < st.NumFields() {
+		return st.Field(i).Name(), true
+	}
+	return "", false
+}
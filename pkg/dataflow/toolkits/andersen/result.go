@@ -0,0 +1,125 @@
+package andersen
+
+import (
+	"go/types"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Result represents the points-to sets of an Andersen analysis, it is safe for concurrent use
+type Result struct {
+	analysis *analysis
+	index    map[*ssa.Function]*funcIndex
+	mu       *sync.Mutex // guards index
+}
+
+// Alias represents a value which may point to the memory another value points to, or to memory inside or around it
+type Alias struct {
+	Value ssa.Value
+	// Path is the access path between the objects of the two values, e.g. Body.x, empty for the same object
+	Path string
+	// Outer reports that the object of the other value is at Path inside the object of Value,
+	// otherwise the object of Value is at Path inside the object of the other value
+	Outer bool
+}
+
+// funcIndex represents the values of a function which point to memory, and the aliases already asked for
+type funcIndex struct {
+	values  []ssa.Value
+	aliases map[ssa.Value][]Alias
+}
+
+// PointsTo returns the objects v may point to, sorted by their names
+func (r *Result) PointsTo(v ssa.Value) []*Object {
+	id, ok := r.analysis.values[v]
+	if !ok {
+		return nil
+	}
+	objects := make([]*Object, 0, len(r.analysis.nodes[id].pts))
+	for o := range r.analysis.nodes[id].pts {
+		objects = append(objects, o)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].String() < objects[j].String() })
+	return objects
+}
+
+// Aliases returns the pointers and slices of f, other than v, which may point to the memory v points to,
+// to a field of it, or to memory it is a field of, in the order they appear in f
+func (r *Result) Aliases(f *ssa.Function, v ssa.Value) []Alias {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index, ok := r.index[f]
+	if !ok {
+		index = &funcIndex{values: memoryValues(f), aliases: make(map[ssa.Value][]Alias)}
+		r.index[f] = index
+	}
+	if aliases, ok := index.aliases[v]; ok {
+		return aliases
+	}
+	aliases := make([]Alias, 0)
+	objects := r.PointsTo(v)
+	for _, u := range index.values {
+		if u == v {
+			continue
+		}
+		seen := make(map[Alias]bool)
+		for _, other := range r.PointsTo(u) {
+			for _, o := range objects {
+				path, inside, ok := o.relation(other)
+				if !ok {
+					continue
+				}
+				alias := Alias{Value: u, Path: path, Outer: !inside}
+				if !seen[alias] {
+					seen[alias] = true
+					aliases = append(aliases, alias)
+				}
+			}
+		}
+	}
+	index.aliases[v] = aliases
+	return aliases
+}
+
+// memoryValues returns the parameters, free vars, globals and registers of f which are pointers or slices
+func memoryValues(f *ssa.Function) []ssa.Value {
+	values := make([]ssa.Value, 0)
+	seen := make(map[ssa.Value]bool)
+	add := func(v ssa.Value) {
+		if seen[v] || !isMemory(v.Type()) {
+			return
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	for _, p := range f.Params {
+		add(p)
+	}
+	for _, fv := range f.FreeVars {
+		add(fv)
+	}
+	for _, b := range f.Blocks {
+		for _, inst := range b.Instrs {
+			for _, op := range inst.Operands(nil) {
+				if g, ok := (*op).(*ssa.Global); ok {
+					add(g)
+				}
+			}
+			if v, ok := inst.(ssa.Value); ok {
+				add(v)
+			}
+		}
+	}
+	return values
+}
+
+// isMemory returns whether values of typ point to memory which can be written through them
+func isMemory(typ types.Type) bool {
+	switch typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package checker
+
+import (
+	"reflect"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/scalar"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/worklist"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// FlowChecker represents a flow analysis run on every source function of a package by go/analysis
+type FlowChecker struct {
+	Name     string
+	Doc      string
+	New      func(g *graph.UnitGraph) scalar.FlowAnalysis // creates the analysis of a function
+	Options  graph.Options                                // options of the graphs of functions
+	Worklist worklist.Kind
+	Budget   solver.Budget // limits of solving one function
+	// Check turns the result of a function into diagnostics, nil means the analyzer only computes results
+	Check func(pass *analysis.Pass, f *ssa.Function, result *solver.Result[*map[any]any]) []analysis.Diagnostic
+}
+
+// Results represents the results of a FlowChecker on a package, keyed by function
+type Results map[*ssa.Function]*solver.Result[*map[any]any]
+
+// NewAnalyzer returns an *analysis.Analyzer running the FlowChecker
+// its result is the Results of the package, so other analyzers can require it
+func NewAnalyzer(c *FlowChecker) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name:       c.Name,
+		Doc:        c.Doc,
+		Requires:   []*analysis.Analyzer{buildssa.Analyzer},
+		ResultType: reflect.TypeOf(Results(nil)),
+		Run: func(pass *analysis.Pass) (any, error) {
+			return c.run(pass)
+		},
+	}
+}
+
+// run solves the analysis of every source function and reports its diagnostics
+func (c *FlowChecker) run(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	results := make(Results)
+	for _, f := range ssaInfo.SrcFuncs {
+		if f.Blocks == nil {
+			continue
+		}
+		g := graph.NewWithOptions(f, c.Options)
+		s := new(solver.Solver)
+		s.Analysis = c.New(g)
+		s.Worklist = c.Worklist
+		s.Budget = c.Budget
+		result := s.DoAnalysis()
+		results[f] = result
+		if c.Check != nil {
+			for _, d := range c.Check(pass, f, result) {
+				pass.Report(d)
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,95 @@
+package graph
+
+import "golang.org/x/tools/go/ssa"
+
+// BlockGraph represents a graph whose units are the leaders of basic blocks
+// a basic block is a maximal chain of units in a UnitGraph where every unit
+// but the last has a single successor, and every unit but the first has a single predecessor
+type BlockGraph struct {
+	UnitGraph
+	Origin        *UnitGraph
+	LeaderToUnits map[ssa.Instruction][]ssa.Instruction
+	UnitToLeader  map[ssa.Instruction]ssa.Instruction
+}
+
+// NewBlockGraph collapses a UnitGraph into a BlockGraph
+func NewBlockGraph(g *UnitGraph) *BlockGraph {
+	blockGraph := new(BlockGraph)
+	blockGraph.Origin = g
+	blockGraph.Func = g.Func
+	blockGraph.UnitChain = make([]ssa.Instruction, 0)
+	blockGraph.Heads = make([]ssa.Instruction, 0)
+	blockGraph.Tails = make([]ssa.Instruction, 0)
+	blockGraph.UnitToSuccs = make(map[ssa.Instruction][]ssa.Instruction)
+	blockGraph.UnitToPreds = make(map[ssa.Instruction][]ssa.Instruction)
+	blockGraph.LeaderToUnits = make(map[ssa.Instruction][]ssa.Instruction)
+	blockGraph.UnitToLeader = make(map[ssa.Instruction]ssa.Instruction, g.Size())
+
+	isHead := make(map[ssa.Instruction]bool)
+	for _, h := range g.Heads {
+		isHead[h] = true
+	}
+	isLeader := func(u ssa.Instruction) bool {
+		preds := g.GetPreds(u)
+		return isHead[u] || len(preds) != 1 || len(g.GetSuccs(preds[0])) != 1
+	}
+
+	// 从每个首指令出发，沿着单一后继收集基本块中的指令
+	collect := func(leader ssa.Instruction) {
+		units := []ssa.Instruction{leader}
+		blockGraph.UnitToLeader[leader] = leader
+		u := leader
+		for {
+			succs := g.GetSuccs(u)
+			if len(succs) != 1 || isLeader(succs[0]) {
+				break
+			}
+			if _, ok := blockGraph.UnitToLeader[succs[0]]; ok {
+				break
+			}
+			u = succs[0]
+			units = append(units, u)
+			blockGraph.UnitToLeader[u] = leader
+		}
+		blockGraph.LeaderToUnits[leader] = units
+		blockGraph.UnitChain = append(blockGraph.UnitChain, leader)
+	}
+	for _, u := range g.UnitChain {
+		if isLeader(u) {
+			collect(u)
+		}
+	}
+	// 没有首指令的环不会被上面访问到，把环上的第一条指令作为首指令
+	for _, u := range g.UnitChain {
+		if _, ok := blockGraph.UnitToLeader[u]; !ok {
+			collect(u)
+		}
+	}
+
+	// 连接基本块
+	for _, leader := range blockGraph.UnitChain {
+		units := blockGraph.LeaderToUnits[leader]
+		last := units[len(units)-1]
+		for _, succ := range g.GetSuccs(last) {
+			blockGraph.UnitToSuccs[leader] = append(blockGraph.UnitToSuccs[leader], blockGraph.UnitToLeader[succ])
+			blockGraph.UnitToPreds[blockGraph.UnitToLeader[succ]] = append(blockGraph.UnitToPreds[blockGraph.UnitToLeader[succ]], leader)
+		}
+	}
+	for _, h := range g.Heads {
+		blockGraph.Heads = append(blockGraph.Heads, blockGraph.UnitToLeader[h])
+	}
+	for _, t := range g.Tails {
+		blockGraph.Tails = append(blockGraph.Tails, blockGraph.UnitToLeader[t])
+	}
+	return blockGraph
+}
+
+// UnitsOf returns the units of the block led by leader
+func (g *BlockGraph) UnitsOf(leader ssa.Instruction) []ssa.Instruction {
+	return g.LeaderToUnits[leader]
+}
+
+// LeaderOf returns the leader of the block which contains unit
+func (g *BlockGraph) LeaderOf(unit ssa.Instruction) ssa.Instruction {
+	return g.UnitToLeader[unit]
+}
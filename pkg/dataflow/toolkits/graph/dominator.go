@@ -0,0 +1,285 @@
+package graph
+
+import "golang.org/x/tools/go/ssa"
+
+// DominatorTree represents the dominator tree of the units of a UnitGraph
+// the tree has a virtual root whose children are the Heads, or the Exits for a post-dominator tree,
+// so a graph with several entries or exits still has one tree
+// units which can not be reached from the roots are not in the tree
+type DominatorTree struct {
+	Graph    *UnitGraph
+	Post     bool                    // whether it is a post-dominator tree
+	nodes    []ssa.Instruction       // units in reverse post order, nodes[0] is the virtual root
+	index    map[ssa.Instruction]int // index of a unit in nodes
+	idom     []int
+	children [][]int
+	frontier [][]int
+	pre      []int // preorder number in the tree
+	last     []int // largest preorder number in the subtree
+}
+
+// NewDominatorTree creates the dominator tree of g
+func NewDominatorTree(g *UnitGraph) *DominatorTree {
+	return newDominatorTree(g, false)
+}
+
+// NewPostDominatorTree creates the post-dominator tree of g
+// it is the dominator tree of the reversed graph rooted at g.Exits()
+func NewPostDominatorTree(g *UnitGraph) *DominatorTree {
+	return newDominatorTree(g, true)
+}
+
+// newDominatorTree computes the tree by the algorithm of Cooper, Harvey and Kennedy
+func newDominatorTree(g *UnitGraph, post bool) *DominatorTree {
+	t := new(DominatorTree)
+	t.Graph = g
+	t.Post = post
+	roots, succs, preds := g.Heads, g.GetSuccs, g.GetPreds
+	if post {
+		roots, succs, preds = g.Exits(), g.GetPreds, g.GetSuccs
+	}
+
+	// 按逆后序给结点编号，虚拟根的编号为 0
+	order := postorder(roots, succs)
+	n := len(order) + 1
+	t.nodes = make([]ssa.Instruction, n)
+	t.index = make(map[ssa.Instruction]int, n)
+	for i, u := range order {
+		t.nodes[n-1-i] = u
+		t.index[u] = n - 1 - i
+	}
+	predIndex := make([][]int, n)
+	for _, r := range roots {
+		predIndex[t.index[r]] = append(predIndex[t.index[r]], 0)
+	}
+	for i := 1; i < n; i++ {
+		for _, p := range preds(t.nodes[i]) {
+			if j, ok := t.index[p]; ok {
+				predIndex[i] = append(predIndex[i], j)
+			}
+		}
+	}
+
+	// 迭代求直接支配者直到不动点
+	t.idom = make([]int, n)
+	for i := range t.idom {
+		t.idom[i] = -1
+	}
+	t.idom[0] = 0
+	for changed := true; changed; {
+		changed = false
+		for i := 1; i < n; i++ {
+			newIdom := -1
+			for _, p := range predIndex[i] {
+				if t.idom[p] == -1 {
+					continue
+				}
+				if newIdom == -1 {
+					newIdom = p
+				} else {
+					newIdom = t.intersect(p, newIdom)
+				}
+			}
+			if t.idom[i] != newIdom {
+				t.idom[i] = newIdom
+				changed = true
+			}
+		}
+	}
+
+	t.children = make([][]int, n)
+	for i := 1; i < n; i++ {
+		t.children[t.idom[i]] = append(t.children[t.idom[i]], i)
+	}
+	t.number()
+
+	// 支配边界：从有多个前驱的结点的每个前驱向上走到它的直接支配者
+	t.frontier = make([][]int, n)
+	inFrontier := make([]map[int]bool, n)
+	for i := 1; i < n; i++ {
+		if len(predIndex[i]) < 2 {
+			continue
+		}
+		for _, p := range predIndex[i] {
+			for runner := p; runner != t.idom[i]; runner = t.idom[runner] {
+				if inFrontier[runner] == nil {
+					inFrontier[runner] = make(map[int]bool)
+				}
+				if !inFrontier[runner][i] {
+					inFrontier[runner][i] = true
+					t.frontier[runner] = append(t.frontier[runner], i)
+				}
+			}
+		}
+	}
+	return t
+}
+
+// intersect returns the nearest common dominator of a and b
+func (t *DominatorTree) intersect(a int, b int) int {
+	for a != b {
+		for a > b {
+			a = t.idom[a]
+		}
+		for b > a {
+			b = t.idom[b]
+		}
+	}
+	return a
+}
+
+// number numbers the tree in preorder, so that a dominates b iff pre[a] <= pre[b] <= last[a]
+func (t *DominatorTree) number() {
+	n := len(t.nodes)
+	t.pre = make([]int, n)
+	t.last = make([]int, n)
+	next := 0
+	stack := []int{0}
+	visited := make([]bool, n)
+	for len(stack) != 0 {
+		i := stack[len(stack)-1]
+		if !visited[i] {
+			visited[i] = true
+			t.pre[i] = next
+			next++
+			for j := len(t.children[i]) - 1; j >= 0; j-- {
+				stack = append(stack, t.children[i][j])
+			}
+			continue
+		}
+		stack = stack[:len(stack)-1]
+		t.last[i] = next - 1
+	}
+}
+
+// units returns the units of indexes
+func (t *DominatorTree) units(indexes []int) []ssa.Instruction {
+	units := make([]ssa.Instruction, 0, len(indexes))
+	for _, i := range indexes {
+		units = append(units, t.nodes[i])
+	}
+	return units
+}
+
+// Contains returns whether u is in the tree
+func (t *DominatorTree) Contains(u ssa.Instruction) bool {
+	_, ok := t.index[u]
+	return ok
+}
+
+// Roots returns the children of the virtual root
+func (t *DominatorTree) Roots() []ssa.Instruction {
+	return t.units(t.children[0])
+}
+
+// Idom returns the immediate dominator of u, or nil if it is the virtual root or u is not in the tree
+func (t *DominatorTree) Idom(u ssa.Instruction) ssa.Instruction {
+	i, ok := t.index[u]
+	if !ok || t.idom[i] == 0 {
+		return nil
+	}
+	return t.nodes[t.idom[i]]
+}
+
+// Children returns the units immediately dominated by u
+func (t *DominatorTree) Children(u ssa.Instruction) []ssa.Instruction {
+	i, ok := t.index[u]
+	if !ok {
+		return nil
+	}
+	return t.units(t.children[i])
+}
+
+// Dominates returns whether a dominates b, a unit dominates itself
+func (t *DominatorTree) Dominates(a ssa.Instruction, b ssa.Instruction) bool {
+	i, ok := t.index[a]
+	j, ok2 := t.index[b]
+	if !ok || !ok2 {
+		return false
+	}
+	return t.pre[i] <= t.pre[j] && t.pre[j] <= t.last[i]
+}
+
+// StrictlyDominates returns whether a dominates b and a is not b
+func (t *DominatorTree) StrictlyDominates(a ssa.Instruction, b ssa.Instruction) bool {
+	return a != b && t.Dominates(a, b)
+}
+
+// Frontier returns the dominance frontier of u, which are the units where the dominance of u ends
+// for a post-dominator tree, it is the post-dominance frontier
+func (t *DominatorTree) Frontier(u ssa.Instruction) []ssa.Instruction {
+	i, ok := t.index[u]
+	if !ok {
+		return nil
+	}
+	return t.units(t.frontier[i])
+}
+
+// ControlDependenceGraph represents the control dependences between the units of a UnitGraph
+// a unit depends on a branch if the branch decides whether the unit runs,
+// that is, the branch is in the post-dominance frontier of the unit
+type ControlDependenceGraph struct {
+	Graph        *UnitGraph
+	PostDom      *DominatorTree
+	dependencies map[ssa.Instruction][]ssa.Instruction
+	dependents   map[ssa.Instruction][]ssa.Instruction
+}
+
+// NewControlDependenceGraph creates the control dependence graph of g
+func NewControlDependenceGraph(g *UnitGraph) *ControlDependenceGraph {
+	cdg := new(ControlDependenceGraph)
+	cdg.Graph = g
+	cdg.PostDom = NewPostDominatorTree(g)
+	cdg.dependencies = make(map[ssa.Instruction][]ssa.Instruction)
+	cdg.dependents = make(map[ssa.Instruction][]ssa.Instruction)
+	for _, u := range g.UnitChain {
+		for _, branch := range cdg.PostDom.Frontier(u) {
+			cdg.dependencies[u] = append(cdg.dependencies[u], branch)
+			cdg.dependents[branch] = append(cdg.dependents[branch], u)
+		}
+	}
+	return cdg
+}
+
+// DependenciesOf returns the branches which u depends on
+func (cdg *ControlDependenceGraph) DependenciesOf(u ssa.Instruction) []ssa.Instruction {
+	return cdg.dependencies[u]
+}
+
+// DependentsOf returns the units which depend on branch
+func (cdg *ControlDependenceGraph) DependentsOf(branch ssa.Instruction) []ssa.Instruction {
+	return cdg.dependents[branch]
+}
+
+// postorder returns units reachable from roots in depth first postorder
+func postorder(roots []ssa.Instruction, succs func(ssa.Instruction) []ssa.Instruction) []ssa.Instruction {
+	order := make([]ssa.Instruction, 0)
+	visited := make(map[ssa.Instruction]bool)
+	type frame struct {
+		u    ssa.Instruction
+		next int
+	}
+	for _, r := range roots {
+		if visited[r] {
+			continue
+		}
+		visited[r] = true
+		stack := []frame{{u: r}}
+		for len(stack) != 0 {
+			top := &stack[len(stack)-1]
+			ss := succs(top.u)
+			if top.next < len(ss) {
+				v := ss[top.next]
+				top.next++
+				if !visited[v] {
+					visited[v] = true
+					stack = append(stack, frame{u: v})
+				}
+				continue
+			}
+			order = append(order, top.u)
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return order
+}
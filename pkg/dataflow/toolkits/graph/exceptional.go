@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"go/token"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Options represents the options of building a UnitGraph
+type Options struct {
+	// Exceptional models deferred calls as units at the exits of the function,
+	// and adds edges from units which may panic to the deferred calls and the recover block
+	Exceptional bool
+}
+
+// DeferredCall represents the call registered by a Defer when it runs at the exit of a function
+// it is a synthetic unit, a UnitGraph with exceptional edges chains one DeferredCall per Defer,
+// in reverse order, after every RunDefers and on the path of panics
+type DeferredCall struct {
+	*ssa.Defer
+	Panicking bool // whether the call runs because of a panic
+}
+
+// String returns the deferred call
+func (d *DeferredCall) String() string {
+	return "deferred " + d.Call.String()
+}
+
+// NewWithOptions creates a UnitGraph with options
+func NewWithOptions(f *ssa.Function, opts Options) *UnitGraph {
+	unitGraph := New(f)
+	if opts.Exceptional {
+		unitGraph.addExceptionalEdges()
+	}
+	return unitGraph
+}
+
+// addExceptionalEdges inserts the deferred calls and the edges of panics
+// every Defer in the function is assumed to have run when it exits,
+// a Panic flows to the deferred calls, so it is no longer a tail
+func (g *UnitGraph) addExceptionalEdges() {
+	units := make([]ssa.Instruction, len(g.UnitChain))
+	copy(units, g.UnitChain)
+	defers := make([]*ssa.Defer, 0)
+	for _, u := range units {
+		if d, ok := u.(*ssa.Defer); ok {
+			defers = append(defers, d)
+		}
+	}
+	if len(defers) == 0 {
+		return
+	}
+
+	// 在每个 RunDefers 和它的后继之间插入延迟调用
+	for _, u := range units {
+		if _, ok := u.(*ssa.RunDefers); !ok {
+			continue
+		}
+		first, last := g.newDeferChain(defers, false)
+		succs := g.UnitToSuccs[u]
+		g.UnitToSuccs[u] = []ssa.Instruction{first}
+		g.UnitToPreds[first] = []ssa.Instruction{u}
+		for _, s := range succs {
+			g.replacePred(s, u, last)
+			g.UnitToSuccs[last] = append(g.UnitToSuccs[last], s)
+		}
+	}
+
+	// 可能发生 panic 的指令先执行延迟调用，再转到 recover 块
+	// recover 块和 RunDefers 之后的指令发生 panic 时延迟调用已经执行过，不再加边
+	first, last := g.newDeferChain(defers, true)
+	for _, b := range g.Func.Blocks {
+		if b == g.Func.Recover {
+			continue
+		}
+		for _, u := range b.Instrs {
+			if _, ok := u.(*ssa.RunDefers); ok {
+				break
+			}
+			if canPanic(u) {
+				g.addEdge(u, first)
+			}
+		}
+	}
+	// panic 现在流向延迟调用，不再是尾指令
+	tails := make([]ssa.Instruction, 0, len(g.Tails))
+	for _, u := range g.Tails {
+		if len(g.UnitToSuccs[u]) == 0 {
+			tails = append(tails, u)
+		}
+	}
+	g.Tails = tails
+	if r := g.Func.Recover; r != nil && len(r.Instrs) != 0 {
+		g.addEdge(last, r.Instrs[0])
+	} else {
+		g.Tails = append(g.Tails, last)
+	}
+}
+
+// newDeferChain appends a chain of deferred calls in reverse order of defers to the graph
+// and returns its first and last units
+func (g *UnitGraph) newDeferChain(defers []*ssa.Defer, panicking bool) (ssa.Instruction, ssa.Instruction) {
+	var first, last ssa.Instruction
+	for i := len(defers) - 1; i >= 0; i-- {
+		call := &DeferredCall{Defer: defers[i], Panicking: panicking}
+		g.UnitChain = append(g.UnitChain, call)
+		if last == nil {
+			first = call
+		} else {
+			g.addEdge(last, call)
+		}
+		last = call
+	}
+	return first, last
+}
+
+// addEdge adds an edge from u to v
+func (g *UnitGraph) addEdge(u ssa.Instruction, v ssa.Instruction) {
+	g.UnitToSuccs[u] = append(g.UnitToSuccs[u], v)
+	g.UnitToPreds[v] = append(g.UnitToPreds[v], u)
+}
+
+// replacePred replaces the predecessor old of u by new
+func (g *UnitGraph) replacePred(u ssa.Instruction, old ssa.Instruction, new ssa.Instruction) {
+	preds := g.UnitToPreds[u]
+	for i, p := range preds {
+		if p == old {
+			preds[i] = new
+		}
+	}
+}
+
+// canPanic returns whether a unit may panic
+func canPanic(u ssa.Instruction) bool {
+	switch inst := u.(type) {
+	case *ssa.Call, *ssa.Panic, *ssa.Index, *ssa.IndexAddr, *ssa.Lookup, *ssa.Slice,
+		*ssa.FieldAddr, *ssa.Store, *ssa.MapUpdate, *ssa.Send, *ssa.SliceToArrayPointer:
+		return true
+	case *ssa.TypeAssert:
+		return !inst.CommaOk
+	case *ssa.UnOp:
+		return inst.Op == token.MUL
+	case *ssa.BinOp:
+		return inst.Op == token.QUO || inst.Op == token.REM
+	}
+	return false
+}
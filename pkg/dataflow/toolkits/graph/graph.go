@@ -89,3 +89,83 @@ func (g *UnitGraph) GetSuccs(inst ssa.Instruction) []ssa.Instruction {
 func (g *UnitGraph) GetPreds(inst ssa.Instruction) []ssa.Instruction {
 	return g.UnitToPreds[inst]
 }
+
+// Exits returns the exit points of the graph for backward analyses
+// they are the Tails, which contain every return and panic, plus a synthetic
+// exit for every infinite loop whose units can never reach a tail
+func (g *UnitGraph) Exits() []ssa.Instruction {
+	exits := make([]ssa.Instruction, 0, len(g.Tails))
+	exits = append(exits, g.Tails...)
+
+	// 标记所有能到达尾指令的指令
+	reached := make(map[ssa.Instruction]bool)
+	g.markBackward(reached, g.Tails...)
+
+	// 按前序遍历的逆序寻找不能到达尾指令的循环回边，把回边的起点作为合成出口
+	order := g.preorder()
+	index := make(map[ssa.Instruction]int, len(order))
+	for i, u := range order {
+		index[u] = i
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		u := order[i]
+		if reached[u] {
+			continue
+		}
+		for _, v := range g.GetSuccs(u) {
+			if index[v] <= i {
+				exits = append(exits, u)
+				g.markBackward(reached, u)
+				break
+			}
+		}
+	}
+	return exits
+}
+
+// markBackward marks all units which can reach one of the roots
+func (g *UnitGraph) markBackward(marked map[ssa.Instruction]bool, roots ...ssa.Instruction) {
+	worklist := make([]ssa.Instruction, 0, len(roots))
+	for _, r := range roots {
+		if !marked[r] {
+			marked[r] = true
+			worklist = append(worklist, r)
+		}
+	}
+	for len(worklist) != 0 {
+		u := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		for _, p := range g.GetPreds(u) {
+			if !marked[p] {
+				marked[p] = true
+				worklist = append(worklist, p)
+			}
+		}
+	}
+}
+
+// preorder returns units reachable from Heads in depth first preorder
+func (g *UnitGraph) preorder() []ssa.Instruction {
+	order := make([]ssa.Instruction, 0, g.Size())
+	visited := make(map[ssa.Instruction]bool, g.Size())
+	stack := make([]ssa.Instruction, 0)
+	for i := len(g.Heads) - 1; i >= 0; i-- {
+		stack = append(stack, g.Heads[i])
+	}
+	for len(stack) != 0 {
+		u := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[u] {
+			continue
+		}
+		visited[u] = true
+		order = append(order, u)
+		succs := g.GetSuccs(u)
+		for i := len(succs) - 1; i >= 0; i-- {
+			if !visited[succs[i]] {
+				stack = append(stack, succs[i])
+			}
+		}
+	}
+	return order
+}
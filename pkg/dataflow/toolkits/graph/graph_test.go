@@ -0,0 +1,320 @@
+package graph
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+const src = `package p
+
+func diamond(c bool) int {
+	x := 0
+	if c {
+		x = 1
+	} else {
+		x = 2
+	}
+	return x
+}
+
+func nested(n int) int {
+	s := 0
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			s += j
+		}
+	}
+	return s
+}
+
+func irreducible(c bool) int {
+	x := 0
+	if c {
+		goto a
+	}
+b:
+	x++
+a:
+	x++
+	if x < 10 {
+		goto b
+	}
+	return x
+}
+
+func spin(n int) {
+	for {
+		n++
+	}
+}
+
+func guarded(p *int) (r int) {
+	defer func() { recover() }()
+	return *p
+}
+
+func panics(c bool) {
+	defer func() {}()
+	if c {
+		panic("c")
+	}
+}
+`
+
+// newGraph returns the graph of the function name of src
+func newGraph(t *testing.T, name string, opts Options) *UnitGraph {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := types.NewPackage("p", "p")
+	ssaPkg, _, err := ssautil.BuildPackage(&types.Config{Importer: importer.Default()}, fset, pkg, []*ast.File{file}, ssa.SanityCheckFunctions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewWithOptions(ssaPkg.Func(name), opts)
+}
+
+// unit returns the unit of g named like nameOf
+func unit(t *testing.T, g *UnitGraph, name string) ssa.Instruction {
+	t.Helper()
+	for _, u := range g.UnitChain {
+		if nameOf(u) == name {
+			return u
+		}
+	}
+	t.Fatalf("no unit %s in %s", name, g.Func)
+	return nil
+}
+
+// nameOf returns "b.i" for the i'th instruction of block b, and "deferred" or "deferred!" for
+// a DeferredCall which runs at a return or on a panic
+func nameOf(u ssa.Instruction) string {
+	if u == nil {
+		return ""
+	}
+	if d, ok := u.(*DeferredCall); ok {
+		if d.Panicking {
+			return "deferred!"
+		}
+		return "deferred"
+	}
+	return fmt.Sprintf("%d.%d", u.Block().Index, slices.Index(u.Block().Instrs, u))
+}
+
+// namesOf returns the names of units, sorted
+func namesOf(units []ssa.Instruction) []string {
+	names := make([]string, 0, len(units))
+	for _, u := range units {
+		names = append(names, nameOf(u))
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestDominatorTree(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		post bool
+		idom map[string]string // immediate dominators, "" is the virtual root
+	}{
+		{"diamond", false, map[string]string{"0.0": "", "1.0": "0.0", "3.0": "0.0", "2.0": "0.0", "2.1": "2.0"}},
+		{"diamond", true, map[string]string{"2.1": "", "2.0": "2.1", "1.0": "2.0", "3.0": "2.0", "0.0": "2.0"}},
+		{"nested", false, map[string]string{"1.0": "0.0", "2.0": "1.3", "3.0": "1.3", "4.0": "2.0", "5.0": "4.3", "6.0": "4.3"}},
+		{"nested", true, map[string]string{"0.0": "1.0", "1.3": "3.0", "2.0": "4.0", "4.3": "6.0", "5.2": "4.0", "6.1": "1.0"}},
+		// neither block of the cycle dominates the other
+		{"irreducible", false, map[string]string{"1.0": "0.0", "2.0": "0.0", "3.0": "1.3"}},
+		// the jump back is the synthetic exit of the infinite loop
+		{"spin", true, map[string]string{"1.2": "", "1.1": "1.2", "1.0": "1.1", "0.0": "1.0"}},
+	} {
+		g := newGraph(t, tc.name, Options{})
+		tree := NewDominatorTree(g)
+		if tc.post {
+			tree = NewPostDominatorTree(g)
+		}
+		for u, want := range tc.idom {
+			if got := nameOf(tree.Idom(unit(t, g, u))); got != want {
+				t.Errorf("%s post=%v: idom of %s is %q, want %q", tc.name, tc.post, u, got, want)
+			}
+			if want != "" && !tree.StrictlyDominates(unit(t, g, want), unit(t, g, u)) {
+				t.Errorf("%s post=%v: %s does not strictly dominate %s", tc.name, tc.post, want, u)
+			}
+		}
+	}
+}
+
+func TestFrontier(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		frontier map[string][]string
+	}{
+		{"diamond", map[string][]string{"0.0": {}, "1.0": {"2.0"}, "3.0": {"2.0"}, "2.0": {}}},
+		{"nested", map[string][]string{"2.0": {"1.0"}, "4.0": {"1.0", "4.0"}, "5.0": {"4.0"}, "3.0": {}}},
+		{"irreducible", map[string][]string{"1.0": {"2.0"}, "2.0": {"1.0"}, "3.0": {}}},
+	} {
+		tree := NewDominatorTree(newGraph(t, tc.name, Options{}))
+		for u, want := range tc.frontier {
+			if got := namesOf(tree.Frontier(unit(t, tree.Graph, u))); !slices.Equal(got, want) {
+				t.Errorf("%s: frontier of %s is %v, want %v", tc.name, u, got, want)
+			}
+		}
+	}
+}
+
+func TestControlDependenceGraph(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		deps map[string][]string
+	}{
+		{"diamond", map[string][]string{"0.0": {}, "1.0": {"0.0"}, "3.0": {"0.0"}, "2.0": {}}},
+		{"nested", map[string][]string{"1.0": {"1.3"}, "2.0": {"1.3"}, "3.0": {}, "4.0": {"1.3", "4.3"}, "5.0": {"4.3"}, "6.0": {"1.3"}}},
+	} {
+		cdg := NewControlDependenceGraph(newGraph(t, tc.name, Options{}))
+		for u, want := range tc.deps {
+			if got := namesOf(cdg.DependenciesOf(unit(t, cdg.Graph, u))); !slices.Equal(got, want) {
+				t.Errorf("%s: %s depends on %v, want %v", tc.name, u, got, want)
+			}
+			for _, branch := range want {
+				if !slices.Contains(namesOf(cdg.DependentsOf(unit(t, cdg.Graph, branch))), u) {
+					t.Errorf("%s: %s is not a dependent of %s", tc.name, u, branch)
+				}
+			}
+		}
+	}
+}
+
+func TestLoops(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		loops []string // header, back edges, exits and size of the body of every loop
+	}{
+		{"diamond", []string{}},
+		{"nested", []string{"1.0 back [6.1] exits [1.3->3.0] 14", "4.0 back [5.2] exits [4.3->6.0] 7"}},
+		// the cycle has two entries, so it has no back edge
+		{"irreducible", []string{}},
+		{"spin", []string{"1.0 back [1.2] exits [] 3"}},
+	} {
+		g := newGraph(t, tc.name, Options{})
+		got := make([]string, 0)
+		for _, loop := range g.Loops() {
+			back := make([]string, 0)
+			for _, e := range loop.BackEdges {
+				back = append(back, nameOf(e.From))
+				if !loop.Contains(e.From) {
+					t.Errorf("%s: back edge from %s is not in the loop", tc.name, nameOf(e.From))
+				}
+			}
+			exits := make([]string, 0)
+			for _, e := range loop.Exits {
+				exits = append(exits, nameOf(e.From)+"->"+nameOf(e.To))
+			}
+			got = append(got, fmt.Sprintf("%s back %v exits %v %d", nameOf(loop.Header), back, exits, len(loop.Body)))
+		}
+		if !slices.Equal(got, tc.loops) {
+			t.Errorf("%s: loops %q, want %q", tc.name, got, tc.loops)
+		}
+	}
+}
+
+func TestSCCs(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cycles []string // first unit and size of every component which is a cycle
+	}{
+		{"diamond", []string{}},
+		{"nested", []string{"1.0 14"}},
+		{"irreducible", []string{"1.0 7"}},
+		{"spin", []string{"1.0 3"}},
+	} {
+		g := newGraph(t, tc.name, Options{})
+		got := make([]string, 0)
+		n := 0
+		for _, scc := range g.SCCs() {
+			n += len(scc)
+			if len(scc) > 1 || slices.Contains(g.GetSuccs(scc[0]), scc[0]) {
+				got = append(got, fmt.Sprintf("%s %d", nameOf(scc[0]), len(scc)))
+			}
+		}
+		if n != g.Size() {
+			t.Errorf("%s: components have %d units, the graph has %d", tc.name, n, g.Size())
+		}
+		if !slices.Equal(got, tc.cycles) {
+			t.Errorf("%s: cycles %q, want %q", tc.name, got, tc.cycles)
+		}
+	}
+}
+
+func TestExits(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		opts  Options
+		exits []string
+	}{
+		{"diamond", Options{}, []string{"2.1"}},
+		{"nested", Options{}, []string{"3.0"}},
+		// an infinite loop has a synthetic exit at its back edge
+		{"spin", Options{}, []string{"1.2"}},
+		// the recover block is only reached by a panic
+		{"guarded", Options{}, []string{"0.6", "1.1"}},
+		{"guarded", Options{Exceptional: true}, []string{"0.6", "1.1"}},
+		// the panic flows through the deferred call to the recover block, so it is no longer an exit
+		{"panics", Options{}, []string{"1.0", "2.1", "3.1"}},
+		{"panics", Options{Exceptional: true}, []string{"1.0", "3.1"}},
+	} {
+		g := newGraph(t, tc.name, tc.opts)
+		if got := namesOf(g.Exits()); !slices.Equal(got, tc.exits) {
+			t.Errorf("%s %+v: exits %v, want %v", tc.name, tc.opts, got, tc.exits)
+		}
+	}
+}
+
+func TestExceptionalEdges(t *testing.T) {
+	for _, tc := range []struct {
+		opts  Options
+		succs map[string][]string
+	}{
+		{Options{}, map[string][]string{"0.2": {"0.3"}, "0.4": {"0.5"}}},
+		// the deferred call runs after rundefers, and after the load and the store which may panic
+		{Options{Exceptional: true}, map[string][]string{
+			"0.1":       {"0.2"},
+			"0.2":       {"0.3", "deferred!"},
+			"0.3":       {"0.4", "deferred!"},
+			"0.4":       {"deferred"},
+			"deferred":  {"0.5"},
+			"deferred!": {"1.0"},
+		}},
+	} {
+		g := newGraph(t, "guarded", tc.opts)
+		for u, want := range tc.succs {
+			if got := namesOf(g.GetSuccs(unit(t, g, u))); !slices.Equal(got, want) {
+				t.Errorf("%+v: successors of %s are %v, want %v", tc.opts, u, got, want)
+			}
+			for _, v := range want {
+				if !slices.Contains(namesOf(g.GetPreds(unit(t, g, v))), u) {
+					t.Errorf("%+v: %s is not a predecessor of %s", tc.opts, u, v)
+				}
+			}
+		}
+	}
+
+	// the recover block is dominated by the panicking deferred call
+	g := newGraph(t, "guarded", Options{Exceptional: true})
+	dom := NewDominatorTree(g)
+	if got := nameOf(dom.Idom(unit(t, g, "1.0"))); got != "deferred!" {
+		t.Errorf("idom of the recover block is %q, want deferred!", got)
+	}
+	if got := nameOf(dom.Idom(unit(t, g, "deferred!"))); got != "0.2" {
+		t.Errorf("idom of the panicking deferred call is %q, want 0.2", got)
+	}
+}
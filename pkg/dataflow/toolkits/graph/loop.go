@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"sort"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Edge represents an edge between two units
+type Edge struct {
+	From ssa.Instruction
+	To   ssa.Instruction
+}
+
+// Loop represents a natural loop of a UnitGraph
+// loops sharing a header are merged, so a header has one loop
+type Loop struct {
+	Header    ssa.Instruction
+	Body      []ssa.Instruction // units of the loop including the header, in order of the UnitChain
+	BackEdges []Edge            // edges from the body to the header
+	Exits     []Edge            // edges from the body to units out of the loop
+	units     map[ssa.Instruction]bool
+}
+
+// Contains returns whether u is in the loop
+func (l *Loop) Contains(u ssa.Instruction) bool {
+	return l.units[u]
+}
+
+// Loops returns the natural loops of the graph, in order of their headers in the UnitChain
+// a back edge goes to a unit which dominates its source, cycles of an irreducible graph
+// have no such edge and are only reported by SCCs
+func (g *UnitGraph) Loops() []*Loop {
+	dom := NewDominatorTree(g)
+	position := g.positions()
+	loops := make([]*Loop, 0)
+	headerToLoop := make(map[ssa.Instruction]*Loop)
+	for _, u := range g.UnitChain {
+		for _, h := range g.GetSuccs(u) {
+			if !dom.Dominates(h, u) {
+				continue
+			}
+			loop, ok := headerToLoop[h]
+			if !ok {
+				loop = new(Loop)
+				loop.Header = h
+				loop.units = map[ssa.Instruction]bool{h: true}
+				headerToLoop[h] = loop
+				loops = append(loops, loop)
+			}
+			loop.BackEdges = append(loop.BackEdges, Edge{From: u, To: h})
+			// 从回边的起点反向走到循环头，经过的结点都属于循环体
+			worklist := []ssa.Instruction{u}
+			for len(worklist) != 0 {
+				v := worklist[len(worklist)-1]
+				worklist = worklist[:len(worklist)-1]
+				if loop.units[v] {
+					continue
+				}
+				loop.units[v] = true
+				worklist = append(worklist, g.GetPreds(v)...)
+			}
+		}
+	}
+
+	for _, loop := range loops {
+		for u := range loop.units {
+			loop.Body = append(loop.Body, u)
+		}
+		sort.Slice(loop.Body, func(i, j int) bool {
+			return position[loop.Body[i]] < position[loop.Body[j]]
+		})
+		for _, u := range loop.Body {
+			for _, v := range g.GetSuccs(u) {
+				if !loop.units[v] {
+					loop.Exits = append(loop.Exits, Edge{From: u, To: v})
+				}
+			}
+		}
+	}
+	sort.Slice(loops, func(i, j int) bool {
+		return position[loops[i].Header] < position[loops[j].Header]
+	})
+	return loops
+}
+
+// SCCs returns the strongly connected components of the graph by Tarjan's algorithm
+// components are in reverse topological order, a component comes before the components which can reach it,
+// and units of a component are in order of the UnitChain
+// a component with one unit is a cycle only if the unit is its own successor
+func (g *UnitGraph) SCCs() [][]ssa.Instruction {
+	position := g.positions()
+	index := make(map[ssa.Instruction]int, g.Size())
+	lowlink := make(map[ssa.Instruction]int, g.Size())
+	onStack := make(map[ssa.Instruction]bool, g.Size())
+	stack := make([]ssa.Instruction, 0)
+	sccs := make([][]ssa.Instruction, 0)
+	type frame struct {
+		u    ssa.Instruction
+		next int
+	}
+	next := 0
+	for _, root := range g.UnitChain {
+		if _, ok := index[root]; ok {
+			continue
+		}
+		index[root], lowlink[root] = next, next
+		next++
+		stack = append(stack, root)
+		onStack[root] = true
+		frames := []frame{{u: root}}
+		for len(frames) != 0 {
+			top := &frames[len(frames)-1]
+			u := top.u
+			succs := g.GetSuccs(u)
+			if top.next < len(succs) {
+				v := succs[top.next]
+				top.next++
+				if _, ok := index[v]; !ok {
+					index[v], lowlink[v] = next, next
+					next++
+					stack = append(stack, v)
+					onStack[v] = true
+					frames = append(frames, frame{u: v})
+				} else if onStack[v] && index[v] < lowlink[u] {
+					lowlink[u] = index[v]
+				}
+				continue
+			}
+			frames = frames[:len(frames)-1]
+			if len(frames) != 0 {
+				parent := frames[len(frames)-1].u
+				if lowlink[u] < lowlink[parent] {
+					lowlink[parent] = lowlink[u]
+				}
+			}
+			if lowlink[u] != index[u] {
+				continue
+			}
+			// u 是强连通分量的根，弹出整个分量
+			scc := make([]ssa.Instruction, 0)
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				scc = append(scc, w)
+				if w == u {
+					break
+				}
+			}
+			sort.Slice(scc, func(i, j int) bool {
+				return position[scc[i]] < position[scc[j]]
+			})
+			sccs = append(sccs, scc)
+		}
+	}
+	return sccs
+}
+
+// positions returns the position of every unit in the UnitChain
+func (g *UnitGraph) positions() map[ssa.Instruction]int {
+	position := make(map[ssa.Instruction]int, g.Size())
+	for i, u := range g.UnitChain {
+		position[u] = i
+	}
+	return position
+}
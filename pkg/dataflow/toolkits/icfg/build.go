@@ -0,0 +1,129 @@
+package icfg
+
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/callgraph/static"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Algorithm represents an algorithm resolving call sites
+type Algorithm int
+
+const (
+	// Static resolves only static calls
+	Static Algorithm = iota
+	// CHA resolves dynamic calls to every function an InterfaceHierarchy allows
+	CHA
+	// VTA refines CHA by the types that can flow to the receivers
+	VTA
+	// RTA resolves dynamic calls to the types reachable from main packages
+	RTA
+)
+
+// String returns the name of the Algorithm
+func (a Algorithm) String() string {
+	switch a {
+	case Static:
+		return "static"
+	case CHA:
+		return "cha"
+	case VTA:
+		return "vta"
+	case RTA:
+		return "rta"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseAlgorithm returns the Algorithm of a name returned by Algorithm.String
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, a := range []Algorithm{Static, CHA, VTA, RTA} {
+		if a.String() == name {
+			return a, nil
+		}
+	}
+	return Static, fmt.Errorf("unknown call graph algorithm %q", name)
+}
+
+// NoMainError represents that RTA finds no main package to start from
+type NoMainError struct {
+}
+
+func (e *NoMainError) Error() string {
+	return "No main functions found in program"
+}
+
+// Build creates an ICFG of prog whose call graph is built by algorithm
+func Build(prog *ssa.Program, algorithm Algorithm) (*ICFG, error) {
+	funcs := ssautil.AllFunctions(prog)
+	var cg *callgraph.Graph
+	switch algorithm {
+	case Static:
+		cg = static.CallGraph(prog)
+	case CHA:
+		cg = NewInterfaceHierarchy(&funcs).CallGraph(funcs)
+	case VTA:
+		cg = vta.CallGraph(funcs, NewInterfaceHierarchy(&funcs).CallGraph(funcs))
+	case RTA:
+		roots := make([]*ssa.Function, 0)
+		for _, main := range ssautil.MainPackages(prog.AllPackages()) {
+			if f := main.Func("main"); f != nil {
+				roots = append(roots, f)
+			}
+			if f := main.Func("init"); f != nil {
+				roots = append(roots, f)
+			}
+		}
+		if len(roots) == 0 {
+			return nil, new(NoMainError)
+		}
+		cg = rta.Analyze(roots, true).CallGraph
+	default:
+		return nil, fmt.Errorf("unknown call graph algorithm %v", algorithm)
+	}
+	cg.DeleteSyntheticNodes()
+	return New(cg), nil
+}
+
+// CallGraph builds a call graph of allFuncs, it resolves invokes by LookupMethods
+// and calls of function values by LookupFuncs
+func (i *InterfaceHierarchy) CallGraph(allFuncs map[*ssa.Function]bool) *callgraph.Graph {
+	cg := callgraph.New(nil)
+	for f := range allFuncs {
+		fnode := cg.CreateNode(f)
+		for _, b := range f.Blocks {
+			for _, inst := range b.Instrs {
+				site, ok := inst.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				for _, callee := range i.lookupCallees(site.Common()) {
+					callgraph.AddEdge(fnode, site, cg.CreateNode(callee))
+				}
+			}
+		}
+	}
+	return cg
+}
+
+// lookupCallees returns the functions a call may call
+func (i *InterfaceHierarchy) lookupCallees(common *ssa.CallCommon) []*ssa.Function {
+	if common.IsInvoke() {
+		tiface := common.Value.Type().Underlying().(*types.Interface)
+		return i.LookupMethods(tiface, common.Method)
+	}
+	if callee := common.StaticCallee(); callee != nil {
+		return []*ssa.Function{callee}
+	}
+	if _, ok := common.Value.(*ssa.Builtin); ok {
+		return nil
+	}
+	return i.LookupFuncs(common.Signature())
+}
@@ -0,0 +1,263 @@
+package icfg
+
+import (
+	"sort"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// ICFG represents an interprocedural control flow graph
+// it joins the UnitGraphs of functions, which are built on demand,
+// through the call sites resolved by a call graph
+// a call site has call edges to the start points of its callees and a call-to-return edge
+// to its return sites, and an exit has return edges to the return sites of the callers
+// with exceptional UnitGraphs, a deferred call is the call site of a Defer
+type ICFG struct {
+	CallGraph *callgraph.Graph
+	Options   graph.Options // options of building the UnitGraphs
+	graphs    map[*ssa.Function]*graph.UnitGraph
+	callees   map[ssa.Instruction][]*ssa.Function
+	callers   map[*ssa.Function][]ssa.Instruction
+}
+
+// New creates an ICFG whose call sites are resolved by cg
+func New(cg *callgraph.Graph) *ICFG {
+	icfg := new(ICFG)
+	icfg.CallGraph = cg
+	icfg.graphs = make(map[*ssa.Function]*graph.UnitGraph)
+	icfg.callees = make(map[ssa.Instruction][]*ssa.Function)
+	icfg.callers = make(map[*ssa.Function][]ssa.Instruction)
+	return icfg
+}
+
+// GraphOf returns the UnitGraph of fn, or nil if fn has no body
+func (g *ICFG) GraphOf(fn *ssa.Function) *graph.UnitGraph {
+	if fn == nil || len(fn.Blocks) == 0 {
+		return nil
+	}
+	if ug, ok := g.graphs[fn]; ok {
+		return ug
+	}
+	ug := graph.NewWithOptions(fn, g.Options)
+	g.graphs[fn] = ug
+	return ug
+}
+
+// MethodOf returns the function containing inst
+func (g *ICFG) MethodOf(inst ssa.Instruction) *ssa.Function {
+	return inst.Parent()
+}
+
+// GetSuccs returns the intraprocedural successors of inst
+func (g *ICFG) GetSuccs(inst ssa.Instruction) []ssa.Instruction {
+	ug := g.GraphOf(inst.Parent())
+	if ug == nil {
+		return nil
+	}
+	return ug.GetSuccs(inst)
+}
+
+// GetPreds returns the intraprocedural predecessors of inst
+func (g *ICFG) GetPreds(inst ssa.Instruction) []ssa.Instruction {
+	ug := g.GraphOf(inst.Parent())
+	if ug == nil {
+		return nil
+	}
+	return ug.GetPreds(inst)
+}
+
+// IsCall returns whether inst is a call site, including go and defer statements,
+// a defer statement is replaced by its deferred calls with exceptional UnitGraphs
+func (g *ICFG) IsCall(inst ssa.Instruction) bool {
+	if _, ok := inst.(*ssa.Defer); ok && g.Options.Exceptional {
+		return false
+	}
+	_, ok := inst.(ssa.CallInstruction)
+	return ok
+}
+
+// IsExit returns whether inst leaves its function, by return or panic
+func (g *ICFG) IsExit(inst ssa.Instruction) bool {
+	return len(g.GetSuccs(inst)) == 0
+}
+
+// IsStartPoint returns whether inst is the first unit of its function
+func (g *ICFG) IsStartPoint(inst ssa.Instruction) bool {
+	for _, sp := range g.StartPointsOf(inst.Parent()) {
+		if sp == inst {
+			return true
+		}
+	}
+	return false
+}
+
+// CalleesOf returns the functions with a body that call site inst may call, sorted by name
+func (g *ICFG) CalleesOf(inst ssa.Instruction) []*ssa.Function {
+	if callees, ok := g.callees[inst]; ok {
+		return callees
+	}
+	var site ssa.Instruction = inst
+	if call, ok := inst.(*graph.DeferredCall); ok {
+		site = call.Defer
+	}
+	callees := make([]*ssa.Function, 0)
+	if node := g.CallGraph.Nodes[inst.Parent()]; node != nil {
+		seen := make(map[*ssa.Function]bool)
+		for _, e := range node.Out {
+			if e.Site != site || seen[e.Callee.Func] || g.GraphOf(e.Callee.Func) == nil {
+				continue
+			}
+			seen[e.Callee.Func] = true
+			callees = append(callees, e.Callee.Func)
+		}
+	}
+	sort.Slice(callees, func(i, j int) bool {
+		return callees[i].String() < callees[j].String()
+	})
+	g.callees[inst] = callees
+	return callees
+}
+
+// CallersOf returns the call sites that may call fn
+func (g *ICFG) CallersOf(fn *ssa.Function) []ssa.Instruction {
+	if callers, ok := g.callers[fn]; ok {
+		return callers
+	}
+	callers := make([]ssa.Instruction, 0)
+	if node := g.CallGraph.Nodes[fn]; node != nil {
+		seen := make(map[ssa.Instruction]bool)
+		for _, e := range node.In {
+			if e.Site == nil || seen[e.Site] || g.GraphOf(e.Caller.Func) == nil {
+				continue
+			}
+			seen[e.Site] = true
+			callers = append(callers, g.callSitesOf(e.Site)...)
+		}
+	}
+	sort.SliceStable(callers, func(i, j int) bool {
+		fi, fj := callers[i].Parent().String(), callers[j].Parent().String()
+		if fi != fj {
+			return fi < fj
+		}
+		return callers[i].Pos() < callers[j].Pos()
+	})
+	g.callers[fn] = callers
+	return callers
+}
+
+// callSitesOf returns the call sites of site, which are the deferred calls of a defer statement
+// with exceptional UnitGraphs
+func (g *ICFG) callSitesOf(site ssa.CallInstruction) []ssa.Instruction {
+	d, ok := site.(*ssa.Defer)
+	if !ok || !g.Options.Exceptional {
+		return []ssa.Instruction{site}
+	}
+	sites := make([]ssa.Instruction, 0)
+	for _, u := range g.GraphOf(d.Parent()).UnitChain {
+		if call, ok := u.(*graph.DeferredCall); ok && call.Defer == d {
+			sites = append(sites, u)
+		}
+	}
+	return sites
+}
+
+// ReturnSitesOf returns the units where the flow goes on after call site inst returns
+func (g *ICFG) ReturnSitesOf(inst ssa.Instruction) []ssa.Instruction {
+	return g.GetSuccs(inst)
+}
+
+// StartPointsOf returns the entry units of fn
+func (g *ICFG) StartPointsOf(fn *ssa.Function) []ssa.Instruction {
+	ug := g.GraphOf(fn)
+	if ug == nil {
+		return nil
+	}
+	return ug.Heads
+}
+
+// EndPointsOf returns the units where fn returns or panics
+func (g *ICFG) EndPointsOf(fn *ssa.Function) []ssa.Instruction {
+	ug := g.GraphOf(fn)
+	if ug == nil {
+		return nil
+	}
+	return ug.Tails
+}
+
+// CallsWithin returns the call sites in fn
+func (g *ICFG) CallsWithin(fn *ssa.Function) []ssa.Instruction {
+	ug := g.GraphOf(fn)
+	if ug == nil {
+		return nil
+	}
+	calls := make([]ssa.Instruction, 0)
+	for _, u := range ug.UnitChain {
+		if g.IsCall(u) {
+			calls = append(calls, u)
+		}
+	}
+	return calls
+}
+
+// IsReturnSite returns whether inst follows a call site
+func (g *ICFG) IsReturnSite(inst ssa.Instruction) bool {
+	for _, pred := range g.GetPreds(inst) {
+		if g.IsCall(pred) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSuperSuccs returns the successors of inst in the supergraph
+// a call site goes to the start points of its callees and its return sites,
+// an exit goes to the return sites of the callers of its function
+func (g *ICFG) GetSuperSuccs(inst ssa.Instruction) []ssa.Instruction {
+	succs := make([]ssa.Instruction, 0)
+	if g.IsCall(inst) {
+		for _, callee := range g.CalleesOf(inst) {
+			succs = append(succs, g.StartPointsOf(callee)...)
+		}
+	}
+	if g.IsExit(inst) {
+		for _, c := range g.CallersOf(inst.Parent()) {
+			succs = append(succs, g.ReturnSitesOf(c)...)
+		}
+	}
+	return append(succs, g.GetSuccs(inst)...)
+}
+
+// GetSuperPreds returns the predecessors of inst in the supergraph
+// a start point comes from the callers of its function,
+// a return site comes from its call site and the exits of the callees
+func (g *ICFG) GetSuperPreds(inst ssa.Instruction) []ssa.Instruction {
+	preds := make([]ssa.Instruction, 0)
+	if g.IsStartPoint(inst) {
+		preds = append(preds, g.CallersOf(inst.Parent())...)
+	}
+	for _, pred := range g.GetPreds(inst) {
+		preds = append(preds, pred)
+		if g.IsCall(pred) {
+			for _, callee := range g.CalleesOf(pred) {
+				preds = append(preds, g.EndPointsOf(callee)...)
+			}
+		}
+	}
+	return preds
+}
+
+// Functions returns the functions with a body in the call graph, sorted by name
+func (g *ICFG) Functions() []*ssa.Function {
+	funcs := make([]*ssa.Function, 0, len(g.CallGraph.Nodes))
+	for fn := range g.CallGraph.Nodes {
+		if g.GraphOf(fn) != nil {
+			funcs = append(funcs, fn)
+		}
+	}
+	sort.Slice(funcs, func(i, j int) bool {
+		return funcs[i].String() < funcs[j].String()
+	})
+	return funcs
+}
@@ -0,0 +1,112 @@
+package ifds
+
+// Identity returns the flow function keeping every fact
+func Identity[D comparable]() FlowFunction[D] {
+	return func(d D) []D {
+		return []D{d}
+	}
+}
+
+// KillAll returns the flow function dropping every fact
+func KillAll[D comparable]() FlowFunction[D] {
+	return func(d D) []D {
+		return nil
+	}
+}
+
+// Gen returns the flow function keeping every fact and generating facts from source
+func Gen[D comparable](source D, facts ...D) FlowFunction[D] {
+	return func(d D) []D {
+		if d == source {
+			return append([]D{d}, facts...)
+		}
+		return []D{d}
+	}
+}
+
+// Kill returns the flow function keeping every fact but the killed ones
+func Kill[D comparable](killed ...D) FlowFunction[D] {
+	return func(d D) []D {
+		for _, k := range killed {
+			if d == k {
+				return nil
+			}
+		}
+		return []D{d}
+	}
+}
+
+// Transfer returns the flow function replacing to by from,
+// it generates to when from holds and kills the old to
+func Transfer[D comparable](to D, from D) FlowFunction[D] {
+	return func(d D) []D {
+		switch d {
+		case from:
+			if from == to {
+				return []D{d}
+			}
+			return []D{d, to}
+		case to:
+			return nil
+		default:
+			return []D{d}
+		}
+	}
+}
+
+// EdgeIdentity represents the edge function which returns its source
+type EdgeIdentity[V any] struct{}
+
+// ComputeTarget returns source
+func (f EdgeIdentity[V]) ComputeTarget(source V) V {
+	return source
+}
+
+// ComposeWith returns second
+func (f EdgeIdentity[V]) ComposeWith(second EdgeFunction[V]) EdgeFunction[V] {
+	return second
+}
+
+// JoinWith returns f if other is an identity or an AllTop, otherwise it leaves the join to other,
+// so an edge function of a problem must be able to join itself with an EdgeIdentity
+func (f EdgeIdentity[V]) JoinWith(other EdgeFunction[V]) EdgeFunction[V] {
+	if _, ok := other.(EdgeIdentity[V]); ok {
+		return f
+	}
+	if _, ok := other.(AllTop[V]); ok {
+		return f
+	}
+	return other.JoinWith(f)
+}
+
+// EqualTo returns whether other is an identity
+func (f EdgeIdentity[V]) EqualTo(other EdgeFunction[V]) bool {
+	_, ok := other.(EdgeIdentity[V])
+	return ok
+}
+
+// AllTop represents the edge function which returns Top for every source
+type AllTop[V any] struct {
+	Value V // Top of the value lattice
+}
+
+// ComputeTarget returns Top
+func (f AllTop[V]) ComputeTarget(source V) V {
+	return f.Value
+}
+
+// ComposeWith returns f, edge functions are expected to map Top to Top
+func (f AllTop[V]) ComposeWith(second EdgeFunction[V]) EdgeFunction[V] {
+	return f
+}
+
+// JoinWith returns other, since Top is the neutral element of the join
+func (f AllTop[V]) JoinWith(other EdgeFunction[V]) EdgeFunction[V] {
+	return other
+}
+
+// EqualTo returns whether other is an AllTop
+func (f AllTop[V]) EqualTo(other EdgeFunction[V]) bool {
+	_, ok := other.(AllTop[V])
+	return ok
+}
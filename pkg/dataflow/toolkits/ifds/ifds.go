@@ -0,0 +1,85 @@
+package ifds
+
+import (
+	"golang.org/x/tools/go/ssa"
+)
+
+// IFDSSolver represents a tabulation solver of IFDS problems
+// it solves the problem as an IDE problem whose edge functions are all identities
+type IFDSSolver[D comparable] struct {
+	Problem Problem[D]
+	ide     *IDESolver[D, bool]
+}
+
+// NewIFDSSolver creates an IFDSSolver of p
+func NewIFDSSolver[D comparable](p Problem[D]) *IFDSSolver[D] {
+	solver := new(IFDSSolver[D])
+	solver.Problem = p
+	solver.ide = NewIDESolver[D, bool](&ifdsProblem[D]{p})
+	solver.ide.values = false
+	return solver
+}
+
+// Solve solves the problem, results can be queried by ResultsAt afterwards
+func (s *IFDSSolver[D]) Solve() {
+	s.ide.Solve()
+}
+
+// ResultsAt returns the facts holding before n in the order they are found, the zero value is left out
+func (s *IFDSSolver[D]) ResultsAt(n ssa.Instruction) []D {
+	return s.ide.factsAt(n)
+}
+
+// Holds returns whether fact d holds before n
+func (s *IFDSSolver[D]) Holds(n ssa.Instruction, d D) bool {
+	_, ok := s.ide.jumpFn[n][d]
+	return ok
+}
+
+// ifdsProblem lifts an IFDS problem to an IDE problem with identity edge functions
+type ifdsProblem[D comparable] struct {
+	Problem[D]
+}
+
+func (p *ifdsProblem[D]) ValueLattice() ValueLattice[bool] {
+	return binaryLattice{}
+}
+
+func (p *ifdsProblem[D]) AllTop() EdgeFunction[bool] {
+	return AllTop[bool]{Value: true}
+}
+
+func (p *ifdsProblem[D]) NormalEdge(curr ssa.Instruction, currFact D, succ ssa.Instruction, succFact D) EdgeFunction[bool] {
+	return EdgeIdentity[bool]{}
+}
+
+func (p *ifdsProblem[D]) CallEdge(callSite ssa.Instruction, srcFact D, callee *ssa.Function, destFact D) EdgeFunction[bool] {
+	return EdgeIdentity[bool]{}
+}
+
+func (p *ifdsProblem[D]) ReturnEdge(callSite ssa.Instruction, callee *ssa.Function, exit ssa.Instruction, exitFact D, returnSite ssa.Instruction, retFact D) EdgeFunction[bool] {
+	return EdgeIdentity[bool]{}
+}
+
+func (p *ifdsProblem[D]) CallToReturnEdge(callSite ssa.Instruction, callFact D, returnSite ssa.Instruction, retFact D) EdgeFunction[bool] {
+	return EdgeIdentity[bool]{}
+}
+
+// binaryLattice represents the lattice of reachability, true means unreachable
+type binaryLattice struct{}
+
+func (binaryLattice) Top() bool {
+	return true
+}
+
+func (binaryLattice) Bottom() bool {
+	return false
+}
+
+func (binaryLattice) Join(x bool, y bool) bool {
+	return x && y
+}
+
+func (binaryLattice) Equal(x bool, y bool) bool {
+	return x == y
+}
@@ -0,0 +1,66 @@
+package ifds
+
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"golang.org/x/tools/go/ssa"
+)
+
+// FlowFunction represents a flow function of an edge in the supergraph,
+// it returns the facts that hold after the edge for a fact d that holds before it
+type FlowFunction[D comparable] func(d D) []D
+
+// Problem represents an IFDS problem whose data flow facts have type D
+// the solver always keeps ZeroValue alive, flow functions need not generate it
+type Problem[D comparable] interface {
+	// ICFG returns the supergraph to solve the problem on
+	ICFG() *icfg.ICFG
+	// ZeroValue returns the fact that holds on every reachable unit
+	ZeroValue() D
+	// InitialSeeds returns the facts that hold before the start units of the analysis
+	InitialSeeds() map[ssa.Instruction][]D
+	// NormalFlow returns the flow function from curr to its successor succ in the same function
+	NormalFlow(curr ssa.Instruction, succ ssa.Instruction) FlowFunction[D]
+	// CallFlow returns the flow function from callSite to the start units of callee
+	CallFlow(callSite ssa.Instruction, callee *ssa.Function) FlowFunction[D]
+	// ReturnFlow returns the flow function from exit of callee back to returnSite of callSite
+	ReturnFlow(callSite ssa.Instruction, callee *ssa.Function, exit ssa.Instruction, returnSite ssa.Instruction) FlowFunction[D]
+	// CallToReturnFlow returns the flow function from callSite to returnSite bypassing the callees
+	// it is also the only flow of a call site without callees
+	CallToReturnFlow(callSite ssa.Instruction, returnSite ssa.Instruction) FlowFunction[D]
+}
+
+// EdgeFunction represents a function on values of type V which annotates
+// an edge between two facts in the exploded supergraph of an IDE problem
+type EdgeFunction[V any] interface {
+	// ComputeTarget returns the value after the edge for source before it
+	ComputeTarget(source V) V
+	// ComposeWith returns the function applying this function and then second
+	ComposeWith(second EdgeFunction[V]) EdgeFunction[V]
+	// JoinWith returns the function joining the results of this function and other
+	JoinWith(other EdgeFunction[V]) EdgeFunction[V]
+	// EqualTo returns whether this function and other are the same function
+	EqualTo(other EdgeFunction[V]) bool
+}
+
+// ValueLattice represents the lattice of values of an IDE problem
+// Top means no information, values move towards Bottom when they are joined
+type ValueLattice[V any] interface {
+	Top() V
+	Bottom() V
+	Join(x V, y V) V
+	Equal(x V, y V) bool
+}
+
+// IDEProblem represents an IDE problem, which annotates the facts of an IFDS problem
+// with values of type V that are computed along the edges by edge functions
+// the values of InitialSeeds are ValueLattice.Bottom
+type IDEProblem[D comparable, V any] interface {
+	Problem[D]
+	ValueLattice() ValueLattice[V]
+	// AllTop returns the edge function mapping every value to Top
+	AllTop() EdgeFunction[V]
+	NormalEdge(curr ssa.Instruction, currFact D, succ ssa.Instruction, succFact D) EdgeFunction[V]
+	CallEdge(callSite ssa.Instruction, srcFact D, callee *ssa.Function, destFact D) EdgeFunction[V]
+	ReturnEdge(callSite ssa.Instruction, callee *ssa.Function, exit ssa.Instruction, exitFact D, returnSite ssa.Instruction, retFact D) EdgeFunction[V]
+	CallToReturnEdge(callSite ssa.Instruction, callFact D, returnSite ssa.Instruction, retFact D) EdgeFunction[V]
+}
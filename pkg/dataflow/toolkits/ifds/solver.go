@@ -0,0 +1,389 @@
+package ifds
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"golang.org/x/tools/go/ssa"
+)
+
+// pathEdge represents an edge from fact d1 at the start of a function to fact d2 at unit n
+type pathEdge[D comparable] struct {
+	d1 D
+	n  ssa.Instruction
+	d2 D
+}
+
+// node represents fact d at unit n in the exploded supergraph
+type node[D comparable] struct {
+	n ssa.Instruction
+	d D
+}
+
+// IDESolver represents a tabulation solver of IDE problems
+// it first computes the jump functions of all path edges and the summaries of functions,
+// then computes the values of facts from the jump functions
+type IDESolver[D comparable, V any] struct {
+	Problem IDEProblem[D, V]
+
+	icfg    *icfg.ICFG
+	zero    D
+	lattice ValueLattice[V]
+	values  bool // whether the second phase computes values
+
+	// jumpFn[n][d2][d1] is the edge function of the path edge from d1 at the start of the function to d2 at n
+	jumpFn map[ssa.Instruction]map[D]map[D]EdgeFunction[V]
+	// facts[n] are the facts reaching n, in the order they are found
+	facts map[ssa.Instruction][]D
+	// incoming[sp][d3][c] are the facts at call site c which flow to d3 at start point sp
+	incoming map[ssa.Instruction]map[D]map[ssa.Instruction]map[D]bool
+	// endSummary[sp][d1][ep][d2] is the edge function from d1 at start point sp to d2 at exit ep
+	endSummary map[ssa.Instruction]map[D]map[ssa.Instruction]map[D]EdgeFunction[V]
+	// val[n][d] is the value of fact d at n
+	val map[ssa.Instruction]map[D]V
+
+	worklist []pathEdge[D]
+}
+
+// NewIDESolver creates an IDESolver of p
+func NewIDESolver[D comparable, V any](p IDEProblem[D, V]) *IDESolver[D, V] {
+	solver := new(IDESolver[D, V])
+	solver.Problem = p
+	solver.values = true
+	return solver
+}
+
+// Solve solves the problem, results can be queried by ResultsAt and ResultAt afterwards
+func (s *IDESolver[D, V]) Solve() {
+	p := s.Problem
+	s.icfg = p.ICFG()
+	s.zero = p.ZeroValue()
+	s.lattice = p.ValueLattice()
+	s.jumpFn = make(map[ssa.Instruction]map[D]map[D]EdgeFunction[V])
+	s.facts = make(map[ssa.Instruction][]D)
+	s.incoming = make(map[ssa.Instruction]map[D]map[ssa.Instruction]map[D]bool)
+	s.endSummary = make(map[ssa.Instruction]map[D]map[ssa.Instruction]map[D]EdgeFunction[V])
+	s.val = make(map[ssa.Instruction]map[D]V)
+	s.worklist = make([]pathEdge[D], 0)
+
+	seeds := p.InitialSeeds()
+	for _, n := range s.orderOf(seeds) {
+		s.propagate(s.zero, n, s.zero, EdgeIdentity[V]{})
+		for _, d := range seeds[n] {
+			s.propagate(s.zero, n, d, EdgeIdentity[V]{})
+		}
+	}
+
+	// phase 1: compute jump functions
+	for len(s.worklist) != 0 {
+		e := s.worklist[0]
+		s.worklist = s.worklist[1:]
+		if s.icfg.IsCall(e.n) {
+			s.processCall(e)
+		} else {
+			s.processNormal(e)
+		}
+		// a deferred call may also be an exit
+		if s.icfg.IsExit(e.n) {
+			s.processExit(e)
+		}
+	}
+
+	// phase 2: compute values
+	if s.values {
+		s.computeValues(seeds)
+	}
+}
+
+// orderOf returns the units of seeds ordered by their functions and their positions in the functions,
+// so the order of solving does not depend on the order of the map
+func (s *IDESolver[D, V]) orderOf(seeds map[ssa.Instruction][]D) []ssa.Instruction {
+	units := make([]ssa.Instruction, 0, len(seeds))
+	position := make(map[ssa.Instruction]int, len(seeds))
+	for n := range seeds {
+		units = append(units, n)
+		if ug := s.icfg.GraphOf(s.icfg.MethodOf(n)); ug != nil {
+			position[n] = slices.Index(ug.UnitChain, n)
+		}
+	}
+	sort.Slice(units, func(i, j int) bool {
+		fi, fj := s.icfg.MethodOf(units[i]).String(), s.icfg.MethodOf(units[j]).String()
+		if fi != fj {
+			return fi < fj
+		}
+		return position[units[i]] < position[units[j]]
+	})
+	return units
+}
+
+// ResultsAt returns the facts holding before n with their values, the zero value is left out
+func (s *IDESolver[D, V]) ResultsAt(n ssa.Instruction) map[D]V {
+	results := make(map[D]V)
+	for _, d := range s.facts[n] {
+		if d != s.zero {
+			results[d] = s.ResultAt(n, d)
+		}
+	}
+	return results
+}
+
+// ResultAt returns the value of fact d before n, or Top if d does not hold there
+func (s *IDESolver[D, V]) ResultAt(n ssa.Instruction, d D) V {
+	if v, ok := s.val[n][d]; ok {
+		return v
+	}
+	return s.lattice.Top()
+}
+
+// factsAt returns the facts holding before n, the zero value is left out
+func (s *IDESolver[D, V]) factsAt(n ssa.Instruction) []D {
+	facts := make([]D, 0, len(s.facts[n]))
+	for _, d := range s.facts[n] {
+		if d != s.zero {
+			facts = append(facts, d)
+		}
+	}
+	return facts
+}
+
+// apply applies a flow function to d, keeping the zero value alive
+func (s *IDESolver[D, V]) apply(f FlowFunction[D], d D) []D {
+	facts := f(d)
+	if d != s.zero {
+		return facts
+	}
+	for _, fact := range facts {
+		if fact == s.zero {
+			return facts
+		}
+	}
+	return append(facts, s.zero)
+}
+
+// propagate joins f into the jump function of the path edge from d1 to d2 at n,
+// and schedules the path edge if its jump function changes
+func (s *IDESolver[D, V]) propagate(d1 D, n ssa.Instruction, d2 D, f EdgeFunction[V]) {
+	byD2, ok := s.jumpFn[n]
+	if !ok {
+		byD2 = make(map[D]map[D]EdgeFunction[V])
+		s.jumpFn[n] = byD2
+	}
+	byD1, ok := byD2[d2]
+	if !ok {
+		byD1 = make(map[D]EdgeFunction[V])
+		byD2[d2] = byD1
+		s.facts[n] = append(s.facts[n], d2)
+	}
+	if old, ok := byD1[d1]; ok {
+		f = old.JoinWith(f)
+		if f.EqualTo(old) {
+			return
+		}
+	}
+	byD1[d1] = f
+	s.worklist = append(s.worklist, pathEdge[D]{d1, n, d2})
+}
+
+// processCall flows a path edge into the callees of a call site and to its return sites
+func (s *IDESolver[D, V]) processCall(e pathEdge[D]) {
+	p := s.Problem
+	n, d1, d2 := e.n, e.d1, e.d2
+	f := s.jumpFn[n][d2][d1]
+	returnSites := s.icfg.ReturnSitesOf(n)
+
+	for _, callee := range s.icfg.CalleesOf(n) {
+		for _, d3 := range s.apply(p.CallFlow(n, callee), d2) {
+			fc := p.CallEdge(n, d2, callee, d3)
+			for _, sp := range s.icfg.StartPointsOf(callee) {
+				s.addIncoming(sp, d3, n, d2)
+				s.propagate(d3, sp, d3, EdgeIdentity[V]{})
+
+				// apply the summaries the callee already has
+				for ep, byD4 := range s.endSummary[sp][d3] {
+					for d4, fs := range byD4 {
+						for _, r := range returnSites {
+							for _, d5 := range s.apply(p.ReturnFlow(n, callee, ep, r), d4) {
+								fr := p.ReturnEdge(n, callee, ep, d4, r, d5)
+								s.propagate(d1, r, d5, f.ComposeWith(fc).ComposeWith(fs).ComposeWith(fr))
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, r := range returnSites {
+		for _, d3 := range s.apply(p.CallToReturnFlow(n, r), d2) {
+			s.propagate(d1, r, d3, f.ComposeWith(p.CallToReturnEdge(n, d2, r, d3)))
+		}
+	}
+}
+
+// processExit records a summary of the function of an exit, and returns it to the callers seen so far
+func (s *IDESolver[D, V]) processExit(e pathEdge[D]) {
+	p := s.Problem
+	n, d1, d2 := e.n, e.d1, e.d2
+	f := s.jumpFn[n][d2][d1]
+	fn := s.icfg.MethodOf(n)
+
+	for _, sp := range s.icfg.StartPointsOf(fn) {
+		fs, changed := s.addEndSummary(sp, d1, n, d2, f)
+		if !changed {
+			continue
+		}
+		for c, d4s := range s.incoming[sp][d1] {
+			for _, r := range s.icfg.ReturnSitesOf(c) {
+				for _, d5 := range s.apply(p.ReturnFlow(c, fn, n, r), d2) {
+					fr := p.ReturnEdge(c, fn, n, d2, r, d5)
+					for d4 := range d4s {
+						fc := p.CallEdge(c, d4, fn, d1)
+						for d3, fcaller := range s.jumpFn[c][d4] {
+							s.propagate(d3, r, d5, fcaller.ComposeWith(fc).ComposeWith(fs).ComposeWith(fr))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// processNormal flows a path edge to the successors of its unit
+func (s *IDESolver[D, V]) processNormal(e pathEdge[D]) {
+	p := s.Problem
+	n, d1, d2 := e.n, e.d1, e.d2
+	f := s.jumpFn[n][d2][d1]
+	for _, m := range s.icfg.GetSuccs(n) {
+		for _, d3 := range s.apply(p.NormalFlow(n, m), d2) {
+			s.propagate(d1, m, d3, f.ComposeWith(p.NormalEdge(n, d2, m, d3)))
+		}
+	}
+}
+
+// addIncoming records that d2 at call site c flows to d3 at start point sp
+func (s *IDESolver[D, V]) addIncoming(sp ssa.Instruction, d3 D, c ssa.Instruction, d2 D) {
+	byD3, ok := s.incoming[sp]
+	if !ok {
+		byD3 = make(map[D]map[ssa.Instruction]map[D]bool)
+		s.incoming[sp] = byD3
+	}
+	byC, ok := byD3[d3]
+	if !ok {
+		byC = make(map[ssa.Instruction]map[D]bool)
+		byD3[d3] = byC
+	}
+	if byC[c] == nil {
+		byC[c] = make(map[D]bool)
+	}
+	byC[c][d2] = true
+}
+
+// addEndSummary joins f into the summary from d1 at sp to d2 at ep,
+// it returns the summary and whether it changes
+func (s *IDESolver[D, V]) addEndSummary(sp ssa.Instruction, d1 D, ep ssa.Instruction, d2 D, f EdgeFunction[V]) (EdgeFunction[V], bool) {
+	byD1, ok := s.endSummary[sp]
+	if !ok {
+		byD1 = make(map[D]map[ssa.Instruction]map[D]EdgeFunction[V])
+		s.endSummary[sp] = byD1
+	}
+	byEp, ok := byD1[d1]
+	if !ok {
+		byEp = make(map[ssa.Instruction]map[D]EdgeFunction[V])
+		byD1[d1] = byEp
+	}
+	byD2, ok := byEp[ep]
+	if !ok {
+		byD2 = make(map[D]EdgeFunction[V])
+		byEp[ep] = byD2
+	}
+	if old, ok := byD2[d2]; ok {
+		f = old.JoinWith(f)
+		if f.EqualTo(old) {
+			return old, false
+		}
+	}
+	byD2[d2] = f
+	return f, true
+}
+
+// computeValues computes the values of facts at start points and call sites first,
+// and then the values at other units from the jump functions
+func (s *IDESolver[D, V]) computeValues(seeds map[ssa.Instruction][]D) {
+	p := s.Problem
+	queue := make([]node[D], 0)
+	schedule := func(n ssa.Instruction, d D, v V) {
+		if s.setVal(n, d, v) {
+			queue = append(queue, node[D]{n, d})
+		}
+	}
+	for _, n := range s.orderOf(seeds) {
+		for _, sp := range s.icfg.StartPointsOf(s.icfg.MethodOf(n)) {
+			schedule(sp, s.zero, s.lattice.Bottom())
+		}
+		schedule(n, s.zero, s.lattice.Bottom())
+		for _, d := range seeds[n] {
+			schedule(n, d, s.lattice.Bottom())
+		}
+	}
+
+	// phase 2.1: propagate values between start points and call sites
+	for len(queue) != 0 {
+		x := queue[0]
+		queue = queue[1:]
+		v := s.ResultAt(x.n, x.d)
+		if s.icfg.IsStartPoint(x.n) {
+			for _, c := range s.icfg.CallsWithin(s.icfg.MethodOf(x.n)) {
+				for d2, byD1 := range s.jumpFn[c] {
+					if f, ok := byD1[x.d]; ok {
+						schedule(c, d2, f.ComputeTarget(v))
+					}
+				}
+			}
+		}
+		if s.icfg.IsCall(x.n) {
+			for _, callee := range s.icfg.CalleesOf(x.n) {
+				for _, d3 := range s.apply(p.CallFlow(x.n, callee), x.d) {
+					fc := p.CallEdge(x.n, x.d, callee, d3)
+					for _, sp := range s.icfg.StartPointsOf(callee) {
+						schedule(sp, d3, fc.ComputeTarget(v))
+					}
+				}
+			}
+		}
+	}
+
+	// phase 2.2: compute values at the other units from the start points of their functions
+	for n, byD2 := range s.jumpFn {
+		if s.icfg.IsStartPoint(n) {
+			continue
+		}
+		for _, sp := range s.icfg.StartPointsOf(s.icfg.MethodOf(n)) {
+			for d2, byD1 := range byD2 {
+				for d1, f := range byD1 {
+					if v, ok := s.val[sp][d1]; ok {
+						s.setVal(n, d2, f.ComputeTarget(v))
+					}
+				}
+			}
+		}
+	}
+}
+
+// setVal joins v into the value of d at n, it returns whether the value changes
+func (s *IDESolver[D, V]) setVal(n ssa.Instruction, d D, v V) bool {
+	byD, ok := s.val[n]
+	if !ok {
+		byD = make(map[D]V)
+		s.val[n] = byD
+	}
+	old, ok := byD[d]
+	if ok {
+		v = s.lattice.Join(old, v)
+		if s.lattice.Equal(old, v) {
+			return false
+		}
+	}
+	byD[d] = v
+	return true
+}
@@ -18,3 +18,10 @@ type FlowAnalysis interface {
 	MergeInto(Unit ssa.Instruction, inout *map[any]any, in *map[any]any)
 	End(universe []*entry.Entry)
 }
+
+// BranchFlowAnalysis can be implemented by a forward FlowAnalysis to send
+// different flows to the successors of an If
+// FlowThroughBranch calculate outMap of the edge from inst to succ based on inMap
+type BranchFlowAnalysis interface {
+	FlowThroughBranch(inMap *map[any]any, inst *ssa.If, succ ssa.Instruction, outMap *map[any]any)
+}
@@ -0,0 +1,103 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"time"
+)
+
+// StopReason represents why a solver stopped
+type StopReason int
+
+const (
+	// Fixpoint means the solver reached a fixpoint
+	Fixpoint StopReason = iota
+	// ComputationLimit means the solver computed more than Computations of the analysis
+	ComputationLimit
+	// Canceled means the context of the solver was canceled
+	Canceled
+	// Timeout means the deadline of the context or the time budget passed
+	Timeout
+	// MemoryLimit means the heap grew over the memory budget
+	MemoryLimit
+)
+
+// String returns the name of the reason
+func (r StopReason) String() string {
+	switch r {
+	case Fixpoint:
+		return "fixpoint"
+	case ComputationLimit:
+		return "computation limit"
+	case Canceled:
+		return "canceled"
+	case Timeout:
+		return "timeout"
+	case MemoryLimit:
+		return "memory limit"
+	}
+	return "unknown"
+}
+
+// Budget represents the limits of solving one function, a zero field means no limit
+type Budget struct {
+	Timeout   time.Duration // 求解一个函数的最长时间
+	MaxMemory uint64        // 求解时堆内存的上限，单位为字节
+}
+
+// 每计算这么多次检查一次上下文和预算，读取内存统计的开销较大
+const budgetCheckInterval = 128
+
+// budgetChecker checks the context and the budget of a solver
+type budgetChecker struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	maxMemory uint64
+}
+
+// newBudgetChecker creates a budgetChecker, the time budget is added to the deadline of ctx
+// a nil ctx means context.Background
+func newBudgetChecker(ctx context.Context, budget Budget) *budgetChecker {
+	c := new(budgetChecker)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.ctx, c.cancel = ctx, func() {}
+	if budget.Timeout > 0 {
+		c.ctx, c.cancel = context.WithTimeout(ctx, budget.Timeout)
+	}
+	c.maxMemory = budget.MaxMemory
+	return c
+}
+
+// check returns why the solver should stop, or Fixpoint if it can go on
+func (c *budgetChecker) check() StopReason {
+	if err := c.ctx.Err(); err != nil {
+		return ReasonOf(err)
+	}
+	if c.maxMemory != 0 && HeapAlloc() > c.maxMemory {
+		return MemoryLimit
+	}
+	return Fixpoint
+}
+
+// ReasonOf returns the StopReason of an error of a context
+func ReasonOf(err error) StopReason {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Timeout
+	}
+	return Canceled
+}
+
+// HeapAlloc returns the bytes of allocated heap objects
+func HeapAlloc() uint64 {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// MarshalText encodes the reason by its name
+func (r StopReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
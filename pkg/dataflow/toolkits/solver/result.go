@@ -0,0 +1,186 @@
+package solver
+
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/typed"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/entry"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/worklist"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Result represents the flows computed by a solver
+type Result[F any] struct {
+	universe   []*entry.Entry
+	entries    map[ssa.Instruction]*entry.Entry
+	inFlow     []F
+	outFlow    []F
+	edgeFlow   [][]F
+	iterations int
+	reason     StopReason
+	worklist   worklist.Kind
+
+	// 以基本块为单位求解时，按需恢复每条指令上的流
+	analysis typed.FlowAnalysis[F]
+	blocks   *graph.BlockGraph
+	units    map[ssa.Instruction]*unitFlow[F]
+}
+
+// unitFlow represents the flows around a unit recovered from its block
+type unitFlow[F any] struct {
+	in  F
+	out F
+}
+
+// newResult builds a Result from the state of a finished solver
+func newResult[F any](universe []*entry.Entry, inFlow []F, outFlow []F, edgeFlow [][]F, iterations int, reason StopReason) *Result[F] {
+	r := new(Result[F])
+	r.universe = universe
+	r.entries = make(map[ssa.Instruction]*entry.Entry, len(universe))
+	for _, e := range universe {
+		r.entries[e.Data] = e
+	}
+	r.inFlow = inFlow
+	r.outFlow = outFlow
+	r.edgeFlow = edgeFlow
+	r.iterations = iterations
+	r.reason = reason
+	return r
+}
+
+// expandBlocks makes a Result of a block level solver recover flows of single units
+func (r *Result[F]) expandBlocks(a typed.FlowAnalysis[F], g *graph.BlockGraph) {
+	r.analysis = a
+	r.blocks = g
+	r.units = make(map[ssa.Instruction]*unitFlow[F])
+}
+
+// unit recovers the flows around every unit in the block of inst by applying
+// the flow functions from the in flow of the block, it returns nil if inst is not in the graph
+func (r *Result[F]) unit(inst ssa.Instruction) *unitFlow[F] {
+	if f, ok := r.units[inst]; ok {
+		return f
+	}
+	leader, ok := r.blocks.UnitToLeader[inst]
+	if !ok {
+		return nil
+	}
+	e, ok := r.entries[leader]
+	if !ok {
+		return nil
+	}
+	units := r.blocks.UnitsOf(leader)
+	n := len(units)
+	flow := r.inFlow[e.Index]
+	for i := 0; i < n; i++ {
+		u := units[i]
+		if !r.analysis.IsForward() {
+			u = units[n-1-i]
+		}
+		f := &unitFlow[F]{in: flow}
+		flow = r.analysis.FlowThrough(flow, u)
+		f.out = flow
+		r.units[u] = f
+	}
+	return r.units[inst]
+}
+
+// In returns the flow before an instruction, in the direction of the analysis,
+// or the zero value of F if the instruction is not in the graph
+func (r *Result[F]) In(inst ssa.Instruction) F {
+	var zero F
+	if r.blocks != nil {
+		if f := r.unit(inst); f != nil {
+			return f.in
+		}
+		return zero
+	}
+	e, ok := r.entries[inst]
+	if !ok {
+		return zero
+	}
+	return r.inFlow[e.Index]
+}
+
+// Out returns the flow after an instruction, in the direction of the analysis,
+// or the zero value of F if the instruction is not in the graph
+func (r *Result[F]) Out(inst ssa.Instruction) F {
+	var zero F
+	if r.blocks != nil {
+		if f := r.unit(inst); f != nil {
+			return f.out
+		}
+		return zero
+	}
+	e, ok := r.entries[inst]
+	if !ok {
+		return zero
+	}
+	return r.outFlow[e.Index]
+}
+
+// Edge returns the flow along the edge from an instruction to one of its successors,
+// in the direction of the analysis, it differs from Out only for branches of
+// an If in a typed.BranchFlowAnalysis
+func (r *Result[F]) Edge(from ssa.Instruction, to ssa.Instruction) F {
+	var zero F
+	if r.blocks != nil {
+		// 只有基本块的最后一条指令有通往其他基本块的边
+		leader := r.blocks.UnitToLeader[from]
+		units := r.blocks.UnitsOf(leader)
+		if len(units) == 0 || units[len(units)-1] != from || !r.analysis.IsForward() {
+			return r.Out(from)
+		}
+		from = leader
+		to = r.blocks.UnitToLeader[to]
+	}
+	e, ok := r.entries[from]
+	if !ok {
+		return zero
+	}
+	if flows := r.edgeFlow[e.Index]; flows != nil {
+		for j, o := range e.Out {
+			if o.Data == to {
+				return flows[j]
+			}
+		}
+	}
+	return r.outFlow[e.Index]
+}
+
+// Has returns whether an instruction is in the solved graph
+func (r *Result[F]) Has(inst ssa.Instruction) bool {
+	if r.blocks != nil {
+		if leader, ok := r.blocks.UnitToLeader[inst]; ok {
+			inst = leader
+		}
+	}
+	_, ok := r.entries[inst]
+	return ok
+}
+
+// Worklist returns the worklist strategy the solver used
+func (r *Result[F]) Worklist() worklist.Kind {
+	return r.worklist
+}
+
+// Iterations returns the number of computations the solver performed
+func (r *Result[F]) Iterations() int {
+	return r.iterations
+}
+
+// Converged returns whether the solver reached a fixpoint, it is false
+// when the solver stopped because Computations, the context or the budget was exceeded
+func (r *Result[F]) Converged() bool {
+	return r.reason == Fixpoint
+}
+
+// StopReason returns why the solver stopped
+func (r *Result[F]) StopReason() StopReason {
+	return r.reason
+}
+
+// Universe returns all entries in reverse post order
+// entries of a block level solver are basic blocks represented by their leaders
+func (r *Result[F]) Universe() []*entry.Entry {
+	return r.universe
+}
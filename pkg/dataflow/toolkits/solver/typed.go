@@ -0,0 +1,314 @@
+package solver
+
+import (
+	"context"
+	"log"
+
+	"github.com/dnote/color"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/typed"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/entry"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/worklist"
+	"golang.org/x/tools/go/ssa"
+)
+
+// TypedSolver 表示一个泛型的流分析求解器
+// 流的类型由 F 决定，不再需要对 *map[any]any 做类型断言
+type TypedSolver[F any] struct {
+	Analysis   typed.FlowAnalysis[F] // 数据流分析的具体实现
+	Debug      bool                  // 是否输出调试信息
+	BlockLevel bool                  // 是否以基本块为单位求解，每个基本块内的指令按顺序应用流函数
+	Worklist   worklist.Kind         // 选择下一个计算的入口的策略，默认为 worklist.FIFO
+	Context    context.Context       // 取消或超时后求解器停止，为 nil 时不会取消
+	Budget     Budget                // 求解这个函数的时间和内存预算
+	universe   []*entry.Entry        // 图中所有节点的入口，按逆后序排列
+	inFlow     []F                   // 每个入口的输入流，下标为 entry.Index
+	outFlow    []F                   // 每个入口的输出流，下标为 entry.Index
+	edgeFlow   [][]F                 // If 入口沿每条出边的流，与 entry.Out 对齐，其他入口为 nil
+
+	branch typed.BranchFlowAnalysis[F] // 如果分析对分支敏感，则不为 nil
+	blocks *graph.BlockGraph           // 以基本块为单位求解时的基本块图，否则为 nil
+
+	widening  typed.WideningAnalysis[F]  // 如果分析需要加宽，则不为 nil
+	narrowing typed.NarrowingAnalysis[F] // 如果分析在加宽之后需要收窄，则不为 nil
+	loopHeads []bool                     // 每个入口是否为循环头，下标为 entry.Index
+
+	budget *budgetChecker // 检查上下文和预算
+}
+
+// SolveTyped 构造一个 TypedSolver 并调用 TypedSolver.DoAnalysis
+// a: 数据流分析的实例，debug: 是否启用调试模式
+func SolveTyped[F any](a typed.FlowAnalysis[F], debug bool) *Result[F] {
+	s := new(TypedSolver[F])
+	s.Analysis = a
+	s.Debug = debug
+	return s.DoAnalysis()
+}
+
+// DoAnalysis 执行数据流分析
+// 返回值为分析结果，包含每条指令的输入流和输出流、计算次数以及停止的原因
+func (s *TypedSolver[F]) DoAnalysis() *Result[F] {
+	a := s.Analysis
+	s.budget = newBudgetChecker(s.Context, s.Budget)
+	defer s.budget.cancel()
+	// 创建分析用的宇宙结构，包含图的所有节点
+	// 以基本块为单位求解时，节点是基本块的首指令
+	g := a.GetGraph()
+	s.blocks = nil
+	if s.BlockLevel {
+		s.blocks = graph.NewBlockGraph(g)
+		g = &s.blocks.UnitGraph
+	}
+	universe, superEntry := newUniverse(g, a.IsForward(), s.Debug)
+	s.universe = universe
+
+	// 只有前向分析的出边才对应 If 的分支
+	s.branch = nil
+	if b, ok := a.(typed.BranchFlowAnalysis[F]); ok && a.IsForward() {
+		s.branch = b
+	}
+
+	// 初始化流的状态
+	s.initFlow(universe, superEntry)
+
+	// 找到循环头，加宽和收窄只在循环头上进行
+	s.widening, s.narrowing = nil, nil
+	if w, ok := a.(typed.WideningAnalysis[F]); ok {
+		s.widening = w
+		if n, ok := a.(typed.NarrowingAnalysis[F]); ok {
+			s.narrowing = n
+		}
+	}
+	hasLoop := s.findLoopHeads(universe)
+
+	// 第一阶段：迭代到不动点，在循环头上加宽
+	// 创建工作表，将所有节点加入工作表中，已在工作表中的节点不会重复加入
+	var widen func(prev F, next F) F
+	if s.widening != nil {
+		widen = s.widening.Widen
+	}
+	numComputations, reason := s.iterate(worklist.New(s.Worklist, universe), 0, widen)
+	if reason != Fixpoint {
+		if s.Debug {
+			color.Set(color.FgYellow)
+			log.Println("stopped solving", a.GetGraph().Func.String(), "after", numComputations, "computations with", s.Worklist, "worklist because of", reason, "skip")
+			color.Unset()
+		}
+		return s.result(numComputations, reason)
+	}
+
+	// 第二阶段：从加宽得到的不动点开始再次迭代，在循环头上收窄
+	// 收窄过程中的每个状态都是安全的，所以超过最大计算次数或预算时直接停止收窄
+	if s.narrowing != nil && hasLoop {
+		n, narrowed := s.iterate(worklist.New(s.Worklist, universe), numComputations, s.narrowing.Narrow)
+		if narrowed != Fixpoint && s.Debug {
+			color.Set(color.FgYellow)
+			log.Println("stopped narrowing", a.GetGraph().Func.String(), "because of", narrowed)
+			color.Unset()
+		}
+		numComputations = n
+	}
+
+	if s.Debug {
+		log.Println("solved", a.GetGraph().Func.String(), "with", s.Worklist, "worklist in", numComputations, "computations")
+	}
+	return s.result(numComputations, Fixpoint)
+}
+
+// 从工作表中取出节点计算直到工作表为空，返回累计的计算次数以及停止的原因
+// q: 工作表，start: 之前的计算次数，atLoopHead: 不为 nil 时用它合并循环头的旧输入流和新输入流
+func (s *TypedSolver[F]) iterate(q worklist.Worklist, start int, atLoopHead func(prev F, next F) F) (int, StopReason) {
+	// numComputations 记录计算的次数
+	for numComputations := start; ; numComputations++ {
+		// 每隔一段计算检查上下文和预算
+		if (numComputations-start)%budgetCheckInterval == 0 {
+			if reason := s.budget.check(); reason != Fixpoint {
+				return numComputations, reason
+			}
+		}
+
+		e := q.Poll() // 获取工作表中的下一个节点
+		if e == nil { // 如果工作表为空，分析结束
+			return numComputations, Fixpoint
+		}
+
+		// 计算当前节点的输入流
+		prev := s.inFlow[e.Index]
+		s.meetFlows(e)
+		if atLoopHead != nil && s.loopHeads[e.Index] {
+			s.inFlow[e.Index] = atLoopHead(prev, s.inFlow[e.Index])
+		}
+
+		// 通过流函数更新流状态，如果有变化，将后继节点加入工作表
+		if s.flowThrough(e) {
+			for _, o := range e.Out {
+				q.Add(o)
+			}
+		}
+
+		// 检查是否超过最大计算次数
+		if numComputations-start > s.Analysis.Computations() {
+			return numComputations, ComputationLimit
+		}
+	}
+}
+
+// 标记循环头，返回是否存在循环
+// 循环头是位于强连通分量中并且有来自回边的前驱的入口，回边的起点在逆后序中不早于终点
+func (s *TypedSolver[F]) findLoopHeads(universe []*entry.Entry) bool {
+	n := len(universe)
+	s.loopHeads = make([]bool, n)
+	hasLoop := false
+	for _, e := range universe {
+		if !e.IsRealStronglyConnected {
+			continue
+		}
+		for _, o := range e.In {
+			// 超级入口的下标为 n，它不在任何循环中
+			if o.Index >= e.Index && o.Index < n {
+				s.loopHeads[e.Index] = true
+				hasLoop = true
+				break
+			}
+		}
+	}
+	return hasLoop
+}
+
+// 用求解器的状态构造分析结果
+func (s *TypedSolver[F]) result(numComputations int, reason StopReason) *Result[F] {
+	r := newResult(s.universe, s.inFlow, s.outFlow, s.edgeFlow, numComputations, reason)
+	r.worklist = s.Worklist
+	if s.blocks != nil {
+		r.expandBlocks(s.Analysis, s.blocks)
+	}
+	return r
+}
+
+// 初始化每个入口的输入流和输出流
+// 超级入口排在最后，它的输出流就是边界流
+func (s *TypedSolver[F]) initFlow(universe []*entry.Entry, superEntry *entry.Entry) {
+	n := len(universe)
+	s.inFlow = make([]F, n+1)
+	s.outFlow = make([]F, n+1)
+	s.edgeFlow = make([][]F, n+1)
+	for i, e := range universe {
+		e.Index = i
+		s.inFlow[i] = s.Analysis.Bottom()
+		s.outFlow[i] = s.Analysis.Bottom()
+	}
+	superEntry.Index = n
+	s.inFlow[n] = s.Analysis.EntryFlow()
+	s.outFlow[n] = s.inFlow[n]
+}
+
+// 合并多个输入流到当前入口的输入流
+// e: 当前处理的入口
+func (s *TypedSolver[F]) meetFlows(e *entry.Entry) {
+	if len(e.In) == 1 {
+		// 只有一个输入，直接使用该输入的输出流
+		s.inFlow[e.Index] = s.predFlow(e.In[0], e)
+		return
+	}
+	var in F
+	for i, o := range e.In {
+		if i == 0 {
+			in = s.Analysis.Copy(s.predFlow(o, e)) // 初始化输入流为第一个输入的输出流
+		} else {
+			in = s.join(s.mergeUnit(e), in, s.predFlow(o, e)) // 合并其他输入的输出流
+		}
+	}
+	s.inFlow[e.Index] = in
+}
+
+// 返回从前驱 o 流向 e 的流
+// 如果 o 是分支敏感的 If，使用对应出边上的流，否则使用 o 的输出流
+func (s *TypedSolver[F]) predFlow(o *entry.Entry, e *entry.Entry) F {
+	flows := s.edgeFlow[o.Index]
+	if flows == nil {
+		return s.outFlow[o.Index]
+	}
+	var f F
+	found := false
+	for j, w := range o.Out {
+		if w != e {
+			continue
+		}
+		if !found {
+			f = flows[j]
+			found = true
+		} else {
+			// 两条分支指向同一个后继
+			f = s.join(s.mergeUnit(e), s.Analysis.Copy(f), flows[j])
+		}
+	}
+	if !found {
+		return s.outFlow[o.Index]
+	}
+	return f
+}
+
+// 返回流在入口处汇合的指令
+// 以基本块为单位时，前向分析在第一条指令汇合，后向分析在最后一条指令汇合
+func (s *TypedSolver[F]) mergeUnit(e *entry.Entry) ssa.Instruction {
+	if s.blocks == nil || s.Analysis.IsForward() {
+		return e.Data
+	}
+	units := s.blocks.UnitsOf(e.Data)
+	return units[len(units)-1]
+}
+
+// 合并两个流，如果分析实现了 typed.MergeAnalysis，使用它的 MergeInto
+func (s *TypedSolver[F]) join(unit ssa.Instruction, x F, y F) F {
+	if m, ok := s.Analysis.(typed.MergeAnalysis[F]); ok {
+		return m.MergeInto(unit, x, y)
+	}
+	return s.Analysis.Join(x, y)
+}
+
+// 更新一个入口的输出流，返回值指示输出流是否发生变化
+// e: 当前处理的入口
+func (s *TypedSolver[F]) flowThrough(e *entry.Entry) bool {
+	in := s.inFlow[e.Index]
+	out, last, before := s.transfer(in, e)
+	changed := !s.Analysis.Equal(out, s.outFlow[e.Index])
+	s.outFlow[e.Index] = out
+
+	// 对 If 的每条出边单独计算流
+	if s.branch != nil {
+		if inst, ok := last.(*ssa.If); ok {
+			old := s.edgeFlow[e.Index]
+			flows := make([]F, len(e.Out))
+			for j, o := range e.Out {
+				flows[j] = s.branch.FlowThroughBranch(before, inst, o.Data)
+				if old == nil || !s.Analysis.Equal(flows[j], old[j]) {
+					changed = true
+				}
+			}
+			s.edgeFlow[e.Index] = flows
+		}
+	}
+	return changed
+}
+
+// 对入口应用流函数，返回输出流、最后应用的指令以及它之前的流
+// 以基本块为单位时，按分析方向依次应用基本块中每条指令的流函数
+func (s *TypedSolver[F]) transfer(in F, e *entry.Entry) (F, ssa.Instruction, F) {
+	if s.blocks == nil {
+		return s.Analysis.FlowThrough(in, e.Data), e.Data, in
+	}
+	units := s.blocks.UnitsOf(e.Data)
+	n := len(units)
+	flow, before := in, in
+	var last ssa.Instruction
+	for i := 0; i < n; i++ {
+		if s.Analysis.IsForward() {
+			last = units[i]
+		} else {
+			last = units[n-1-i]
+		}
+		before = flow
+		flow = s.Analysis.FlowThrough(flow, last)
+	}
+	return flow, last, before
+}
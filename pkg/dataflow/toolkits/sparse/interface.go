@@ -0,0 +1,25 @@
+package sparse
+
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Analysis represents a sparse analysis whose facts of type V are attached to SSA values
+// every value defined in the graph has one fact, computed from the facts of its operands,
+// and a changed fact is propagated to the values using it along def-use chains
+type Analysis[V any] interface {
+	GetGraph() *graph.UnitGraph
+	// Bottom returns the fact of a value which has not been computed yet
+	Bottom() V
+	// Join returns the least upper bound of x and y, it must not modify x or y
+	Join(x V, y V) V
+	// Equal returns whether x and y are the same fact
+	Equal(x V, y V) bool
+	// Initial returns the fact of a value whose definition the solver can not see,
+	// such as a parameter, a free variable, a global, a constant or a load of untracked memory
+	Initial(v ssa.Value) V
+	// Transfer returns the fact of the value defined by inst, lookup returns the current facts of other values
+	// loads of memory are computed by the solver and never passed to Transfer
+	Transfer(inst ssa.Value, lookup func(ssa.Value) V) V
+}
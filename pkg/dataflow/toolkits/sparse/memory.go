@@ -0,0 +1,195 @@
+package sparse
+
+import (
+	"go/token"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/typed"
+	"golang.org/x/tools/go/ssa"
+)
+
+// location represents a tracked memory location, a local Alloc or one of its fields
+type location struct {
+	base  *ssa.Alloc
+	field int // index of the field, -1 for the whole Alloc
+}
+
+// cell represents the content of a location, unknown means the solver can not tell,
+// e.g. the zero value of a new Alloc or a field overwritten by a store to the whole struct
+type cell[V any] struct {
+	fact    V
+	unknown bool
+}
+
+// memory represents the contents of the tracked locations at a unit
+// a location missing from the map has not been allocated on any path to the unit
+type memory[V any] map[location]cell[V]
+
+// memoryAnalysis represents the dense flow analysis of tracked memory
+// it reads the facts of stored values from the sparse solver
+type memoryAnalysis[V any] struct {
+	typed.BaseFlowAnalysis
+	solver  *Solver[V]
+	fields  map[*ssa.Alloc][]int // fields of every tracked Alloc which are accessed
+	tracked map[*ssa.Alloc]bool
+}
+
+// newMemoryAnalysis creates a memoryAnalysis of the graph of s
+// only Allocs whose address does not escape are tracked, since no other instruction can touch them
+func newMemoryAnalysis[V any](s *Solver[V]) *memoryAnalysis[V] {
+	m := new(memoryAnalysis[V])
+	m.BaseFlowAnalysis = *typed.NewBase(s.Analysis.GetGraph())
+	m.solver = s
+	m.fields = make(map[*ssa.Alloc][]int)
+	m.tracked = make(map[*ssa.Alloc]bool)
+	for _, u := range m.Graph.UnitChain {
+		alloc, ok := u.(*ssa.Alloc)
+		if !ok || escapes(alloc) {
+			continue
+		}
+		m.tracked[alloc] = true
+		seen := make(map[int]bool)
+		for _, r := range *alloc.Referrers() {
+			if fa, ok := r.(*ssa.FieldAddr); ok && !seen[fa.Field] {
+				seen[fa.Field] = true
+				m.fields[alloc] = append(m.fields[alloc], fa.Field)
+			}
+		}
+	}
+	return m
+}
+
+// escapes returns whether the address of alloc is used other than by loads, stores and field addresses
+// which are used by loads and stores only
+func escapes(alloc *ssa.Alloc) bool {
+	for _, r := range *alloc.Referrers() {
+		if fa, ok := r.(*ssa.FieldAddr); ok {
+			for _, rr := range *fa.Referrers() {
+				if !accesses(rr, fa) {
+					return true
+				}
+			}
+			continue
+		}
+		if !accesses(r, alloc) {
+			return true
+		}
+	}
+	return false
+}
+
+// accesses returns whether inst only loads from or stores to addr
+func accesses(inst ssa.Instruction, addr ssa.Value) bool {
+	switch i := inst.(type) {
+	case *ssa.Store:
+		return i.Addr == addr && i.Val != addr
+	case *ssa.UnOp:
+		return i.Op == token.MUL
+	case *ssa.DebugRef:
+		return true
+	}
+	return false
+}
+
+// locationOf returns the tracked location which addr points to
+func (m *memoryAnalysis[V]) locationOf(addr ssa.Value) (location, bool) {
+	switch a := addr.(type) {
+	case *ssa.Alloc:
+		if m.tracked[a] {
+			return location{base: a, field: -1}, true
+		}
+	case *ssa.FieldAddr:
+		if alloc, ok := a.X.(*ssa.Alloc); ok && m.tracked[alloc] {
+			return location{base: alloc, field: a.Field}, true
+		}
+	}
+	return location{}, false
+}
+
+// Bottom returns an empty memory
+func (m *memoryAnalysis[V]) Bottom() memory[V] {
+	return make(memory[V])
+}
+
+// EntryFlow returns an empty memory, no location has been allocated at the entry
+func (m *memoryAnalysis[V]) EntryFlow() memory[V] {
+	return make(memory[V])
+}
+
+// Join joins the cells of y into x
+func (m *memoryAnalysis[V]) Join(x memory[V], y memory[V]) memory[V] {
+	for l, c := range y {
+		old, ok := x[l]
+		switch {
+		case !ok:
+			x[l] = c
+		case old.unknown || c.unknown:
+			x[l] = cell[V]{unknown: true}
+		default:
+			x[l] = cell[V]{fact: m.solver.Analysis.Join(old.fact, c.fact)}
+		}
+	}
+	return x
+}
+
+// Equal returns whether x and y have the same cells
+func (m *memoryAnalysis[V]) Equal(x memory[V], y memory[V]) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for l, c := range x {
+		c2, ok := y[l]
+		if !ok || c.unknown != c2.unknown {
+			return false
+		}
+		if !c.unknown && !m.solver.Analysis.Equal(c.fact, c2.fact) {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy returns a copy of f
+func (m *memoryAnalysis[V]) Copy(f memory[V]) memory[V] {
+	c := make(memory[V], len(f))
+	for l, v := range f {
+		c[l] = v
+	}
+	return c
+}
+
+// FlowThrough allocates tracked locations and updates them by stores
+func (m *memoryAnalysis[V]) FlowThrough(in memory[V], unit ssa.Instruction) memory[V] {
+	out := m.Copy(in)
+	switch inst := unit.(type) {
+	case *ssa.Alloc:
+		if !m.tracked[inst] {
+			break
+		}
+		// 新分配的内存是零值，不跟踪它的事实
+		out[location{base: inst, field: -1}] = cell[V]{unknown: true}
+		for _, field := range m.fields[inst] {
+			out[location{base: inst, field: field}] = cell[V]{unknown: true}
+		}
+	case *ssa.Store:
+		l, ok := m.locationOf(inst.Addr)
+		if !ok {
+			break
+		}
+		out[l] = cell[V]{fact: m.solver.lookup(inst.Val)}
+		// 写整个结构体会覆盖所有字段，写字段会改变整个结构体
+		if l.field == -1 {
+			for _, field := range m.fields[l.base] {
+				out[location{base: l.base, field: field}] = cell[V]{unknown: true}
+			}
+		} else {
+			out[location{base: l.base, field: -1}] = cell[V]{unknown: true}
+		}
+	}
+	return out
+}
+
+// isLoad returns whether v is a load of memory
+func isLoad(v ssa.Value) bool {
+	u, ok := v.(*ssa.UnOp)
+	return ok && u.Op == token.MUL
+}
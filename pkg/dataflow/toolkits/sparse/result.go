@@ -0,0 +1,52 @@
+package sparse
+
+import "golang.org/x/tools/go/ssa"
+
+// Result represents the facts computed by a sparse solver
+type Result[V any] struct {
+	analysis  Analysis[V]
+	values    []ssa.Value
+	facts     map[ssa.Value]V
+	transfers int
+	rounds    int
+}
+
+// newResult builds a Result from the state of a finished solver
+func newResult[V any](s *Solver[V], transfers int, rounds int) *Result[V] {
+	r := new(Result[V])
+	r.analysis = s.Analysis
+	r.values = s.values
+	r.facts = s.facts
+	r.transfers = transfers
+	r.rounds = rounds
+	return r
+}
+
+// Fact returns the fact of v, values not defined in the graph get Analysis.Initial
+func (r *Result[V]) Fact(v ssa.Value) V {
+	if f, ok := r.facts[v]; ok {
+		return f
+	}
+	return r.analysis.Initial(v)
+}
+
+// Has returns whether v is defined in the graph
+func (r *Result[V]) Has(v ssa.Value) bool {
+	_, ok := r.facts[v]
+	return ok
+}
+
+// Values returns the values defined in the graph, in order of the UnitChain
+func (r *Result[V]) Values() []ssa.Value {
+	return r.values
+}
+
+// Transfers returns the number of times a fact was computed
+func (r *Result[V]) Transfers() int {
+	return r.transfers
+}
+
+// Rounds returns the number of times the dense solver computed memory
+func (r *Result[V]) Rounds() int {
+	return r.rounds
+}
@@ -0,0 +1,146 @@
+package sparse
+
+import (
+	"log"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Solver represents a sparse solver, which propagates facts along def-use chains
+// instead of pushing a flow of every value through every unit
+// memory is not in SSA form, so loads are computed by a dense solver over the stores to
+// local Allocs whose address does not escape, other loads get Analysis.Initial,
+// the dense solver does not run for a function without such Allocs
+// the two solvers take turns until the facts of loads stop changing
+type Solver[V any] struct {
+	Analysis Analysis[V] // 稀疏分析的具体实现
+	Debug    bool        // 是否输出调试信息
+	values   []ssa.Value // 图中定义的值，按指令链的顺序排列
+	defined  map[ssa.Value]bool
+	facts    map[ssa.Value]V
+	loads    map[ssa.Value]V // 稠密求解器算出的读内存的值的事实
+}
+
+// Solve constructs a Solver and calls Solver.DoAnalysis
+func Solve[V any](a Analysis[V], debug bool) *Result[V] {
+	s := new(Solver[V])
+	s.Analysis = a
+	s.Debug = debug
+	return s.DoAnalysis()
+}
+
+// DoAnalysis computes the fact of every value defined in the graph
+func (s *Solver[V]) DoAnalysis() *Result[V] {
+	g := s.Analysis.GetGraph()
+	s.values = make([]ssa.Value, 0)
+	s.defined = make(map[ssa.Value]bool)
+	s.facts = make(map[ssa.Value]V)
+	s.loads = make(map[ssa.Value]V)
+	hasLoad := false
+	for _, u := range g.UnitChain {
+		if v, ok := u.(ssa.Value); ok && u.Block() != nil {
+			s.values = append(s.values, v)
+			s.defined[v] = true
+			hasLoad = hasLoad || isLoad(v)
+		}
+	}
+
+	// 所有的值先进入工作表，之后只有操作数的事实改变的值才会重新计算
+	queue := make([]ssa.Value, len(s.values))
+	copy(queue, s.values)
+	queued := make(map[ssa.Value]bool, len(s.values))
+	for _, v := range s.values {
+		queued[v] = true
+	}
+	transfers, rounds := 0, 0
+	var m *memoryAnalysis[V]
+	for {
+		for len(queue) != 0 {
+			v := queue[0]
+			queue = queue[1:]
+			queued[v] = false
+			transfers++
+			if !s.update(v) {
+				continue
+			}
+			for _, r := range *v.Referrers() {
+				if rv, ok := r.(ssa.Value); ok && s.defined[rv] && !queued[rv] {
+					queued[rv] = true
+					queue = append(queue, rv)
+				}
+			}
+		}
+		if !hasLoad {
+			break
+		}
+
+		// 用稠密求解器计算内存，把读内存的值的新事实加入工作表
+		if m == nil {
+			m = newMemoryAnalysis(s)
+		}
+		// 没有跟踪的 Alloc 时所有读内存的值都是 Initial，不需要稠密求解
+		in := func(ssa.Instruction) memory[V] { return nil }
+		if len(m.tracked) != 0 {
+			rounds++
+			in = solver.SolveTyped[memory[V]](m, false).In
+		}
+		for _, v := range s.values {
+			if !isLoad(v) {
+				continue
+			}
+			fact := s.Analysis.Initial(v)
+			if l, ok := m.locationOf(v.(*ssa.UnOp).X); ok {
+				if c, ok := in(v.(ssa.Instruction))[l]; ok && !c.unknown {
+					fact = c.fact
+				}
+			}
+			if old, ok := s.loads[v]; ok && s.Analysis.Equal(old, fact) {
+				continue
+			}
+			s.loads[v] = fact
+			if !queued[v] {
+				queued[v] = true
+				queue = append(queue, v)
+			}
+		}
+		if len(queue) == 0 {
+			break
+		}
+	}
+
+	if s.Debug {
+		log.Println("sparse solved", g.Func.String(), "with", transfers, "transfers and", rounds, "memory rounds")
+	}
+	return newResult(s, transfers, rounds)
+}
+
+// update recomputes the fact of v and returns whether it has changed
+func (s *Solver[V]) update(v ssa.Value) bool {
+	var fact V
+	if isLoad(v) {
+		if f, ok := s.loads[v]; ok {
+			fact = f
+		} else {
+			fact = s.Analysis.Bottom()
+		}
+	} else {
+		fact = s.Analysis.Transfer(v, s.lookup)
+	}
+	if old, ok := s.facts[v]; ok && s.Analysis.Equal(old, fact) {
+		return false
+	}
+	s.facts[v] = fact
+	return true
+}
+
+// lookup returns the current fact of v
+func (s *Solver[V]) lookup(v ssa.Value) V {
+	if !s.defined[v] {
+		return s.Analysis.Initial(v)
+	}
+	if f, ok := s.facts[v]; ok {
+		return f
+	}
+	return s.Analysis.Bottom()
+}
@@ -0,0 +1,107 @@
+package typed
+
+import (
+	"reflect"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/scalar"
+	"golang.org/x/tools/go/ssa"
+)
+
+// ScalarAdapter adapts a scalar.FlowAnalysis to a FlowAnalysis over *map[any]any,
+// so analyses written against the map based api keep working on the typed solver
+type ScalarAdapter struct {
+	Analysis scalar.FlowAnalysis
+}
+
+// BranchScalarAdapter adapts a scalar.FlowAnalysis which also implements
+// scalar.BranchFlowAnalysis
+type BranchScalarAdapter struct {
+	ScalarAdapter
+	Branch scalar.BranchFlowAnalysis
+}
+
+// FromScalar returns an adapter of a scalar.FlowAnalysis, it is a *BranchScalarAdapter
+// if the analysis implements scalar.BranchFlowAnalysis, or a *ScalarAdapter otherwise
+func FromScalar(a scalar.FlowAnalysis) FlowAnalysis[*map[any]any] {
+	if branch, ok := a.(scalar.BranchFlowAnalysis); ok {
+		adapter := new(BranchScalarAdapter)
+		adapter.Analysis = a
+		adapter.Branch = branch
+		return adapter
+	}
+	adapter := new(ScalarAdapter)
+	adapter.Analysis = a
+	return adapter
+}
+
+// GetGraph returns the graph of the adapted analysis
+func (a *ScalarAdapter) GetGraph() *graph.UnitGraph {
+	return a.Analysis.GetGraph()
+}
+
+// IsForward returns whether the adapted analysis is a forward flow analysis
+func (a *ScalarAdapter) IsForward() bool {
+	return a.Analysis.IsForward()
+}
+
+// Computations returns the computation limit of the adapted analysis
+func (a *ScalarAdapter) Computations() int {
+	return a.Analysis.Computations()
+}
+
+// Bottom returns a new inital flow of the adapted analysis
+func (a *ScalarAdapter) Bottom() *map[any]any {
+	return a.Analysis.NewInitalFlow()
+}
+
+// EntryFlow returns the entry flow of the adapted analysis
+func (a *ScalarAdapter) EntryFlow() *map[any]any {
+	return a.Analysis.EntryInitalFlow()
+}
+
+// Join merges y into x without a unit
+func (a *ScalarAdapter) Join(x *map[any]any, y *map[any]any) *map[any]any {
+	return a.MergeInto(nil, x, y)
+}
+
+// MergeInto merges y into x by MergeInto of the adapted analysis
+func (a *ScalarAdapter) MergeInto(unit ssa.Instruction, x *map[any]any, y *map[any]any) *map[any]any {
+	a.Analysis.MergeInto(unit, x, y)
+	return x
+}
+
+// Equal checks whether two maps have deeply equal values for every key
+func (a *ScalarAdapter) Equal(x *map[any]any, y *map[any]any) bool {
+	if len(*x) != len(*y) {
+		return false
+	}
+	for k, v := range *x {
+		u, ok := (*y)[k]
+		if !ok || !reflect.DeepEqual(v, u) {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy copies f into a new inital flow
+func (a *ScalarAdapter) Copy(f *map[any]any) *map[any]any {
+	m := a.Analysis.NewInitalFlow()
+	a.Analysis.Copy(f, m)
+	return m
+}
+
+// FlowThrough calculates a new out flow by FlowThrougth of the adapted analysis
+func (a *ScalarAdapter) FlowThrough(in *map[any]any, unit ssa.Instruction) *map[any]any {
+	out := a.Analysis.NewInitalFlow()
+	a.Analysis.FlowThrougth(in, unit, out)
+	return out
+}
+
+// FlowThroughBranch calculates a new out flow of an edge by FlowThroughBranch of the adapted analysis
+func (a *BranchScalarAdapter) FlowThroughBranch(in *map[any]any, inst *ssa.If, succ ssa.Instruction) *map[any]any {
+	out := a.Analysis.NewInitalFlow()
+	a.Branch.FlowThroughBranch(in, inst, succ, out)
+	return out
+}
@@ -0,0 +1,35 @@
+package typed
+
+import (
+	"math"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+)
+
+// BaseFlowAnalysis represents a base implemention of the parts of a
+// FlowAnalysis which do not depend on the flow type
+type BaseFlowAnalysis struct {
+	Graph *graph.UnitGraph
+}
+
+// NewBase returns a BaseFlowAnalysis
+func NewBase(g *graph.UnitGraph) *BaseFlowAnalysis {
+	baseFlowAnalysis := new(BaseFlowAnalysis)
+	baseFlowAnalysis.Graph = g
+	return baseFlowAnalysis
+}
+
+// GetGraph returns the Graph memeber in a BaseFlowAnalysis
+func (a *BaseFlowAnalysis) GetGraph() *graph.UnitGraph {
+	return a.Graph
+}
+
+// IsForward returns whether this analysis is a forward flow analysis
+func (a *BaseFlowAnalysis) IsForward() bool {
+	return true
+}
+
+// Computations limit number of computations on a flow graph
+func (a *BaseFlowAnalysis) Computations() int {
+	return math.MaxInt
+}
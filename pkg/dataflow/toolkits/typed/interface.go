@@ -0,0 +1,58 @@
+package typed
+
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Lattice represents a lattice whose elements are flows of type F
+type Lattice[F any] interface {
+	// Bottom returns a new bottom element
+	Bottom() F
+	// Join returns the least upper bound of x and y, it may modify and return x
+	Join(x F, y F) F
+	// Equal returns whether x and y are the same element
+	Equal(x F, y F) bool
+	// Copy returns a copy of f that can be modified independently
+	Copy(f F) F
+}
+
+// FlowAnalysis represents a flow analysis whose flows have type F
+type FlowAnalysis[F any] interface {
+	Lattice[F]
+	GetGraph() *graph.UnitGraph
+	IsForward() bool
+	Computations() int
+	// EntryFlow returns the boundary flow at the entries of the graph,
+	// or at its exits for a backward analysis
+	EntryFlow() F
+	// FlowThrough returns the flow after unit, it must not modify in
+	FlowThrough(in F, unit ssa.Instruction) F
+}
+
+// MergeAnalysis can be implemented by a FlowAnalysis whose join depends on
+// the unit where flows meet, the solver uses it instead of Lattice.Join
+type MergeAnalysis[F any] interface {
+	MergeInto(unit ssa.Instruction, x F, y F) F
+}
+
+// BranchFlowAnalysis can be implemented by a forward FlowAnalysis to send
+// different flows to the successors of an If, the solver calls FlowThroughBranch
+// for every outgoing edge of the If and uses its result instead of FlowThrough
+type BranchFlowAnalysis[F any] interface {
+	FlowThroughBranch(in F, inst *ssa.If, succ ssa.Instruction) F
+}
+
+// WideningAnalysis can be implemented by a FlowAnalysis whose lattice has infinite
+// ascending chains, the solver widens the in flow of every loop head with it
+// so that the analysis converges, Widen must not modify prev or next
+type WideningAnalysis[F any] interface {
+	Widen(prev F, next F) F
+}
+
+// NarrowingAnalysis can be implemented by a WideningAnalysis to recover precision
+// lost by widening, after the widened analysis converges the solver iterates again
+// and narrows the in flow of every loop head with it, Narrow must not modify prev or next
+type NarrowingAnalysis[F any] interface {
+	Narrow(prev F, next F) F
+}
@@ -14,6 +14,7 @@ type Entry struct {
 	In                      []*Entry
 	Out                     []*Entry
 	Number                  int
+	Index                   int
 	IsRealStronglyConnected bool
 }
 
@@ -0,0 +1,173 @@
+package worklist
+
+import (
+	"container/heap"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/util/entry"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/queue"
+)
+
+// Kind represents a strategy of choosing the next entry to compute
+type Kind int
+
+const (
+	// FIFO computes entries in the order they are added
+	FIFO Kind = iota
+	// LIFO computes the entry added last first
+	LIFO
+	// Priority computes the entry with the smallest reverse post order first
+	Priority
+)
+
+// String returns the name of the Kind
+func (k Kind) String() string {
+	switch k {
+	case FIFO:
+		return "fifo"
+	case LIFO:
+		return "lifo"
+	case Priority:
+		return "priority"
+	default:
+		return "unknown"
+	}
+}
+
+// Worklist represents the entries waiting to be computed by a solver
+// an entry that is already in the Worklist is not added again
+type Worklist interface {
+	// Add adds an entry, it does nothing if the entry is already queued
+	Add(e *entry.Entry)
+	// Poll pops and returns the next entry, or nil if the Worklist is empty
+	Poll() *entry.Entry
+	// Len returns the number of queued entries
+	Len() int
+}
+
+// New creates a Worklist of kind k containing all entries of universe
+// entries are identified by entry.Index, which must be in [0, len(universe)]
+func New(k Kind, universe []*entry.Entry) Worklist {
+	var w Worklist
+	queued := make([]bool, len(universe)+1)
+	switch k {
+	case LIFO:
+		w = &stack{queued: queued}
+	case Priority:
+		w = &priority{queued: queued}
+	default:
+		w = &fifo{queue: queue.New(), queued: queued}
+	}
+	// 让 LIFO 也从逆后序的第一个入口开始
+	if k == LIFO {
+		for i := len(universe) - 1; i >= 0; i-- {
+			w.Add(universe[i])
+		}
+	} else {
+		for _, e := range universe {
+			w.Add(e)
+		}
+	}
+	return w
+}
+
+// fifo represents a first in first out Worklist
+type fifo struct {
+	queue  *queue.Queue
+	queued []bool
+}
+
+func (w *fifo) Add(e *entry.Entry) {
+	if w.queued[e.Index] {
+		return
+	}
+	w.queued[e.Index] = true
+	w.queue.Add(e)
+}
+
+func (w *fifo) Poll() *entry.Entry {
+	e := w.queue.Poll()
+	if e != nil {
+		w.queued[e.Index] = false
+	}
+	return e
+}
+
+func (w *fifo) Len() int {
+	return w.queue.Len()
+}
+
+// stack represents a last in first out Worklist
+type stack struct {
+	entries []*entry.Entry
+	queued  []bool
+}
+
+func (w *stack) Add(e *entry.Entry) {
+	if w.queued[e.Index] {
+		return
+	}
+	w.queued[e.Index] = true
+	w.entries = append(w.entries, e)
+}
+
+func (w *stack) Poll() *entry.Entry {
+	n := len(w.entries)
+	if n == 0 {
+		return nil
+	}
+	e := w.entries[n-1]
+	w.entries = w.entries[:n-1]
+	w.queued[e.Index] = false
+	return e
+}
+
+func (w *stack) Len() int {
+	return len(w.entries)
+}
+
+// priority represents a Worklist ordered by reverse post order
+// which computes all predecessors of an entry before it, except along back edges
+type priority struct {
+	entries entryHeap
+	queued  []bool
+}
+
+func (w *priority) Add(e *entry.Entry) {
+	if w.queued[e.Index] {
+		return
+	}
+	w.queued[e.Index] = true
+	heap.Push(&w.entries, e)
+}
+
+func (w *priority) Poll() *entry.Entry {
+	if len(w.entries) == 0 {
+		return nil
+	}
+	e := heap.Pop(&w.entries).(*entry.Entry)
+	w.queued[e.Index] = false
+	return e
+}
+
+func (w *priority) Len() int {
+	return len(w.entries)
+}
+
+// entryHeap implements heap.Interface as a min heap on entry.Index
+type entryHeap []*entry.Entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].Index < h[j].Index }
+func (h entryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *entryHeap) Push(x any) {
+	*h = append(*h, x.(*entry.Entry))
+}
+
+func (h *entryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	*h = old[:n-1]
+	return e
+}
@@ -19,15 +19,21 @@ type ConstantPropagationAnalysis struct {
 	constantPropagationSwitcher *ConstantPropagationSwitcher
 }
 
-// New creates a ConstantPropagationAnalysis
+// New creates a ConstantPropagationAnalysis, and prints the function
 func New(g *graph.UnitGraph) *ConstantPropagationAnalysis {
+	constanctPropagationAnalysis := newAnalysis(g)
+	constanctPropagationAnalysis.Graph.Func.WriteTo(os.Stdout)
+	return constanctPropagationAnalysis
+}
+
+// newAnalysis creates a ConstantPropagationAnalysis without printing
+func newAnalysis(g *graph.UnitGraph) *ConstantPropagationAnalysis {
 	constanctPropagationAnalysis := new(ConstantPropagationAnalysis)
 	constanctPropagationAnalysis.BaseFlowAnalysis = *scalar.NewBase(g)
 	constantPropagationSwitcher := new(ConstantPropagationSwitcher)
 	constantPropagationSwitcher.BaseSwitcher = *new(switcher.BaseSwitcher)
 	constanctPropagationAnalysis.constantPropagationSwitcher = constantPropagationSwitcher
 	constantPropagationSwitcher.constanctPropagationAnalysis = constanctPropagationAnalysis
-	constanctPropagationAnalysis.Graph.Func.WriteTo(os.Stdout)
 	return constanctPropagationAnalysis
 }
 
@@ -46,6 +52,32 @@ func (a *ConstantPropagationAnalysis) FlowThrougth(inMap *map[any]any, unit ssa.
 	a.apply(inMap, unit, outMap)
 }
 
+// MergeInto merge from in to inout based on unit, facts of the same key meet
+func (a *ConstantPropagationAnalysis) MergeInto(unit ssa.Instruction, inout *map[any]any, in *map[any]any) {
+	for k, v := range *in {
+		if old, ok := (*inout)[k]; ok {
+			(*inout)[k] = meet(old, v)
+		} else {
+			(*inout)[k] = v
+		}
+	}
+}
+
+// FlowThroughBranch calculate outMap of the edge from an If to succ based on inMap
+// if the condition is a constant, the infeasible branch gets no facts from inMap
+func (a *ConstantPropagationAnalysis) FlowThroughBranch(inMap *map[any]any, inst *ssa.If, succ ssa.Instruction, outMap *map[any]any) {
+	if taken, ok := condition(inMap, inst.Cond); ok {
+		then, els := inst.Block().Succs[0], inst.Block().Succs[1]
+		if then != els {
+			if (taken && succ.Block() == els) || (!taken && succ.Block() == then) {
+				// infeasible branch, leave outMap as a new inital flow
+				return
+			}
+		}
+	}
+	a.Copy(inMap, outMap)
+}
+
 // End handle result of analysis
 func (a *ConstantPropagationAnalysis) End(universe []*entry.Entry) {
 	for _, v := range universe {
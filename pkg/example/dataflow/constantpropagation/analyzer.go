@@ -0,0 +1,84 @@
+package constantpropagation
+
+import (
+	"fmt"
+	"go/token"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/checker"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/scalar"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/entry"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Analyzer reports conditions which constant propagation proves always true or always false
+var Analyzer = checker.NewAnalyzer(&checker.FlowChecker{
+	Name: "constcond",
+	Doc:  "report if conditions which are always true or always false by constant propagation",
+	New: func(g *graph.UnitGraph) scalar.FlowAnalysis {
+		return &quietAnalysis{newAnalysis(g)}
+	},
+	Check: checkConditions,
+})
+
+// quietAnalysis represents a ConstantPropagationAnalysis which does not print its result
+type quietAnalysis struct {
+	*ConstantPropagationAnalysis
+}
+
+// End does nothing, results are reported as diagnostics
+func (a *quietAnalysis) End(universe []*entry.Entry) {}
+
+// ConstantCondition represents an If whose condition is always true or always false
+type ConstantCondition struct {
+	If    *ssa.If
+	Value bool
+}
+
+// FindConstantConditions solves constant propagation on f and returns its constant conditions
+func FindConstantConditions(f *ssa.Function) []ConstantCondition {
+	g := graph.New(f)
+	s := new(solver.Solver)
+	s.Analysis = &quietAnalysis{newAnalysis(g)}
+	return constantConditions(f, s.DoAnalysis())
+}
+
+// checkConditions reports the constant conditions of f
+func checkConditions(pass *analysis.Pass, f *ssa.Function, result *solver.Result[*map[any]any]) []analysis.Diagnostic {
+	diagnostics := make([]analysis.Diagnostic, 0)
+	for _, c := range constantConditions(f, result) {
+		diagnostics = append(diagnostics, analysis.Diagnostic{Pos: c.Pos(), Message: fmt.Sprintf("condition is always %v", c.Value)})
+	}
+	return diagnostics
+}
+
+// Pos returns the position of the condition, or of its function if the condition has none
+func (c ConstantCondition) Pos() token.Pos {
+	if pos := c.If.Cond.Pos(); pos.IsValid() {
+		return pos
+	}
+	return c.If.Parent().Pos()
+}
+
+// constantConditions returns every If whose condition is a constant in its in flow
+// literal constants are written on purpose, so they are not returned
+func constantConditions(f *ssa.Function, result *solver.Result[*map[any]any]) []ConstantCondition {
+	conditions := make([]ConstantCondition, 0)
+	for _, b := range f.Blocks {
+		inst, ok := b.Instrs[len(b.Instrs)-1].(*ssa.If)
+		if !ok || !result.Has(inst) {
+			continue
+		}
+		if _, ok := inst.Cond.(*ssa.Const); ok {
+			continue
+		}
+		taken, ok := condition(result.In(inst), inst.Cond)
+		if !ok {
+			continue
+		}
+		conditions = append(conditions, ConstantCondition{If: inst, Value: taken})
+	}
+	return conditions
+}
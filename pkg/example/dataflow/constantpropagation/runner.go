@@ -1,15 +1,19 @@
 package constantpropagation
 
 import (
+	"fmt"
 	"go/ast"
 	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"log"
+	"os"
 
+	"github.com/dnote/color"
 	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
 	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/sparse"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 )
@@ -18,6 +22,7 @@ import (
 type Runner struct {
 	Src      string
 	Function string
+	Sparse   bool // propagate facts along def-use chains instead of through every instruction
 }
 
 func NewRunner(src string, function string) *Runner {
@@ -48,9 +53,26 @@ func (r *Runner) Run() {
 	// Build graph
 	graph := graph.New(hello.Func(r.Function))
 
+	if r.Sparse {
+		r.runSparse(graph)
+		return
+	}
+
 	// Build analysis
 	analysis := New(graph)
 
 	// Solve analysis
 	solver.Solve(analysis, true)
 }
+
+// runSparse solves the sparse analysis and prints the fact of every value
+func (r *Runner) runSparse(g *graph.UnitGraph) {
+	g.Func.WriteTo(os.Stdout)
+	result := sparse.Solve[any](NewSparse(g), true)
+	for _, v := range result.Values() {
+		color.Set(color.FgGreen)
+		fmt.Println("constant fact for value: " + v.Name() + " = " + v.String())
+		color.Unset()
+		fmt.Printf("%v=%v\n\n", v.Name(), result.Fact(v))
+	}
+}
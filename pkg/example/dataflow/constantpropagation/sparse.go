@@ -0,0 +1,67 @@
+package constantpropagation
+
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/golang/switcher"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/typed"
+	"golang.org/x/tools/go/ssa"
+)
+
+// SparseConstantPropagationAnalysis represents a constant propagation on def-use chains
+// facts are the same as the dense analysis, an int, a bool, "UNDEF" or "NAC"
+type SparseConstantPropagationAnalysis struct {
+	typed.BaseFlowAnalysis
+	constantPropagationSwitcher *ConstantPropagationSwitcher
+}
+
+// NewSparse creates a SparseConstantPropagationAnalysis
+func NewSparse(g *graph.UnitGraph) *SparseConstantPropagationAnalysis {
+	sparseAnalysis := new(SparseConstantPropagationAnalysis)
+	sparseAnalysis.BaseFlowAnalysis = *typed.NewBase(g)
+	sparseAnalysis.constantPropagationSwitcher = new(ConstantPropagationSwitcher)
+	return sparseAnalysis
+}
+
+// Bottom returns "UNDEF"
+func (a *SparseConstantPropagationAnalysis) Bottom() any {
+	return "UNDEF"
+}
+
+// Join returns the meet of two facts
+func (a *SparseConstantPropagationAnalysis) Join(x any, y any) any {
+	return meet(x, y)
+}
+
+// Equal returns whether x and y are the same fact
+func (a *SparseConstantPropagationAnalysis) Equal(x any, y any) bool {
+	return x == y
+}
+
+// Initial returns the fact of a constant, other values defined outside the function are "NAC"
+func (a *SparseConstantPropagationAnalysis) Initial(v ssa.Value) any {
+	if _, ok := v.(*ssa.Const); ok {
+		return valueOf(nil, v)
+	}
+	return "NAC"
+}
+
+// Transfer applies the switcher of the dense analysis to a flow holding the facts of the operands
+// values the switcher does not evaluate are "NAC"
+func (a *SparseConstantPropagationAnalysis) Transfer(inst ssa.Value, lookup func(ssa.Value) any) any {
+	flow := make(map[any]any)
+	for _, op := range inst.(ssa.Instruction).Operands(nil) {
+		if *op == nil {
+			continue
+		}
+		if _, ok := (*op).(*ssa.Const); !ok {
+			flow[(*op).Name()] = lookup(*op)
+		}
+	}
+	s := a.constantPropagationSwitcher
+	s.inMap, s.outMap = &flow, &flow
+	switcher.Apply(s, inst.(ssa.Instruction))
+	if r, ok := flow[inst.Name()]; ok {
+		return r
+	}
+	return "NAC"
+}
@@ -0,0 +1,122 @@
+package constantpropagation
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/sparse"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// equivalenceSrc has no parameters, calls, memory or constant conditions:
+// the dense analysis takes unknown values as "UNDEF" and prunes infeasible branches, the sparse one does neither
+const equivalenceSrc = `package p
+
+func arith() int {
+	a := 2
+	b := a * 3
+	c := b - 1
+	return c / 2
+}
+
+func loop() int {
+	s, k := 0, 4
+	for i := 0; i < 10; i++ {
+		s += i
+		k = k * 1
+	}
+	return s + k
+}
+
+func branches() int {
+	x := 0
+	for i := 0; i < 3; i++ {
+		if i%2 == 0 {
+			x = 7
+		} else {
+			x = 7
+		}
+	}
+	return x
+}
+
+func nested() int {
+	t, u := 0, 1
+	for i := 0; i < 3; i++ {
+		for j := 0; j < i; j++ {
+			t = 5
+			u = u + 0
+		}
+	}
+	return t + u
+}
+`
+
+// buildPackage returns the SSA package of src
+func buildPackage(t *testing.T, src string) *ssa.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := types.NewPackage("p", "p")
+	ssaPkg, _, err := ssautil.BuildPackage(&types.Config{Importer: importer.Default()}, fset, pkg, []*ast.File{file}, ssa.SanityCheckFunctions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ssaPkg
+}
+
+func TestSparseMatchesDense(t *testing.T) {
+	ssaPkg := buildPackage(t, equivalenceSrc)
+	for _, name := range []string{"arith", "loop", "branches", "nested"} {
+		f := ssaPkg.Func(name)
+		s := new(solver.Solver)
+		s.Analysis = &quietAnalysis{newAnalysis(graph.New(f))}
+		dense := s.DoAnalysis()
+		result := sparse.Solve[any](NewSparse(graph.New(f)), false)
+		if result.Rounds() != 0 {
+			t.Errorf("%s: memory solved %d times without allocs", name, result.Rounds())
+		}
+		constants := 0
+		for _, v := range result.Values() {
+			want := valueOf(dense.Out(v.(ssa.Instruction)), v)
+			if got := result.Fact(v); got != want {
+				t.Errorf("%s: %s = %s is %v in the sparse analysis, %v in the dense one", name, v.Name(), v, got, want)
+			}
+			if _, ok := want.(int); ok {
+				constants++
+			}
+		}
+		if constants == 0 {
+			t.Errorf("%s: no constant is found, the test checks nothing", name)
+		}
+	}
+}
+
+func TestSparseMemory(t *testing.T) {
+	ssaPkg := buildPackage(t, `package p
+
+func field() int {
+	var s struct{ x, y int }
+	s.x = 3
+	return s.x + 1
+}
+`)
+	result := sparse.Solve[any](NewSparse(graph.New(ssaPkg.Func("field"))), false)
+	if result.Rounds() == 0 {
+		t.Error("memory of a local struct is not solved")
+	}
+	values := result.Values()
+	if got := result.Fact(values[len(values)-1]); got != 4 {
+		t.Errorf("s.x + 1 is %v, want 4", got)
+	}
+}
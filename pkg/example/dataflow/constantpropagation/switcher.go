@@ -1,6 +1,7 @@
 package constantpropagation
 
 import (
+	"go/constant"
 	"go/token"
 	"math"
 
@@ -21,8 +22,9 @@ func (s *ConstantPropagationSwitcher) CaseBinOp(inst *ssa.BinOp) {
 	v, u, n := s.lookup(inst.X)
 	v2, u2, n2 := s.lookup(inst.Y)
 	if !u && !u2 && !n && !n2 {
-		res, ok := s.evalBinOp(v, v2, inst.Op)
-		if ok {
+		if res, ok := s.evalBinOp(v, v2, inst.Op); ok {
+			(*s.outMap)[inst.Name()] = res
+		} else if res, ok := s.evalCompare(v, v2, inst.Op); ok {
 			(*s.outMap)[inst.Name()] = res
 		} else {
 			(*s.outMap)[inst.Name()] = "NAC"
@@ -36,59 +38,88 @@ func (s *ConstantPropagationSwitcher) CaseBinOp(inst *ssa.BinOp) {
 
 // CasePhi accepts a Phi instruction
 func (s *ConstantPropagationSwitcher) CasePhi(inst *ssa.Phi) {
+	var res any = "UNDEF"
 	for _, v := range inst.Edges {
-		switch v := (*s.outMap)[v.Name()].(type) {
-		case string:
-			if v == "NAC" {
-				(*s.outMap)[inst.Name()] = "NAC"
-				return
-			}
-		}
+		res = meet(res, valueOf(s.outMap, v))
 	}
-	allsame := true
-	counti := 0
-	i := 0
-	for _, v := range inst.Edges {
-		switch v := (*s.outMap)[v.Name()].(type) {
-		case int:
-			if counti == 0 {
-				counti++
-				i = v
-			} else {
-				if v != i {
-					counti++
-					allsame = false
-				}
-			}
-		}
-		if allsame == false {
-			(*s.outMap)[inst.Name()] = "NAC"
+	(*s.outMap)[inst.Name()] = res
+}
+
+func (s *ConstantPropagationSwitcher) lookup(_v ssa.Value) (int, bool, bool) {
+	switch r := valueOf(s.outMap, _v).(type) {
+	case string:
+		if r == "UNDEF" {
+			return 0, true, false
 		}
+		return 0, false, true
+	case int:
+		return r, false, false
 	}
-	if counti == 0 {
-		(*s.outMap)[inst.Name()] = "UNDEF"
-	} else {
-		(*s.outMap)[inst.Name()] = i
-	}
+	// only integers take part in arithmetic
+	return 0, false, true
 }
 
-func (s *ConstantPropagationSwitcher) lookup(_v ssa.Value) (int, bool, bool) {
+// condition returns the value of a bool condition in a flow and whether it is a constant
+func condition(flow *map[any]any, v ssa.Value) (bool, bool) {
+	b, ok := valueOf(flow, v).(bool)
+	return b, ok
+}
+
+// valueOf returns the fact of a value in a flow, which is an int, a bool, "UNDEF" or "NAC"
+// values missing in the flow have not been defined yet
+func valueOf(flow *map[any]any, _v ssa.Value) any {
 	switch v := (_v).(type) {
 	case *ssa.Const:
-		return int(v.Int64()), false, false
+		if v.Value == nil {
+			return "NAC"
+		}
+		switch v.Value.Kind() {
+		case constant.Int:
+			return int(v.Int64())
+		case constant.Bool:
+			return constant.BoolVal(v.Value)
+		}
+		return "NAC"
 	default:
-		_r := (*s.outMap)[v.Name()]
-		switch r := (_r).(type) {
-		case string:
-			if r == "UNDEF" {
-				return 0, true, false
-			}
-			return 0, false, true
-		case int:
-			return r, false, false
+		r, ok := (*flow)[v.Name()]
+		if !ok {
+			return "UNDEF"
 		}
+		return r
+	}
+}
+
+// meet returns the meet of two facts
+// UNDEF meets anything is the other one, different constants meet NAC
+func meet(x any, y any) any {
+	if x == "UNDEF" {
+		return y
+	}
+	if y == "UNDEF" {
+		return x
+	}
+	if x == y {
+		return x
+	}
+	return "NAC"
+}
+
+func (s *ConstantPropagationSwitcher) evalCompare(x int, y int, op token.Token) (bool, bool) {
+	switch op {
+	case token.EQL:
+		return x == y, true
+	case token.NEQ:
+		return x != y, true
+	case token.LSS:
+		return x < y, true
+	case token.LEQ:
+		return x <= y, true
+	case token.GTR:
+		return x > y, true
+	case token.GEQ:
+		return x >= y, true
 	}
-	return 0, false, false
+	return false, false
 }
 
 func (s *ConstantPropagationSwitcher) evalBinOp(x int, y int, op token.Token) (int, bool) {
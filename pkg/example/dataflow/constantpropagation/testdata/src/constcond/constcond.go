@@ -0,0 +1,32 @@
+package constcond
+
+func Same() int {
+	a := 1
+	b := a + 1
+	if b == 2 { // want "condition is always true"
+		return b
+	}
+	return 0
+}
+
+func Never(n int) int {
+	a := 3
+	if a*2 > 10 { // want "condition is always false"
+		return n
+	}
+	return a
+}
+
+func Unknown(n int) int {
+	if n > 1 {
+		return n
+	}
+	return 0
+}
+
+func Literal() int {
+	if true {
+		return 1
+	}
+	return 0
+}
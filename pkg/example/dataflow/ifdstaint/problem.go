@@ -0,0 +1,146 @@
+package ifdstaint
+
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/ifds"
+	"golang.org/x/tools/go/ssa"
+)
+
+// TaintProblem represents an IFDS problem where a fact is a tainted ssa.Value
+// values returned by calls of Source are tainted, and taint flows through
+// operands, arguments, results and stores, the zero value is nil
+type TaintProblem struct {
+	icfg   *icfg.ICFG
+	entry  *ssa.Function
+	Source string
+	Sink   string
+}
+
+// NewTaintProblem creates a TaintProblem starting from entry
+func NewTaintProblem(g *icfg.ICFG, entry *ssa.Function) *TaintProblem {
+	taintProblem := new(TaintProblem)
+	taintProblem.icfg = g
+	taintProblem.entry = entry
+	taintProblem.Source = "source"
+	taintProblem.Sink = "sink"
+	return taintProblem
+}
+
+// ICFG returns the supergraph of the problem
+func (p *TaintProblem) ICFG() *icfg.ICFG {
+	return p.icfg
+}
+
+// ZeroValue returns nil
+func (p *TaintProblem) ZeroValue() ssa.Value {
+	return nil
+}
+
+// InitialSeeds returns the start points of the entry function
+func (p *TaintProblem) InitialSeeds() map[ssa.Instruction][]ssa.Value {
+	seeds := make(map[ssa.Instruction][]ssa.Value)
+	for _, sp := range p.icfg.StartPointsOf(p.entry) {
+		seeds[sp] = nil
+	}
+	return seeds
+}
+
+// NormalFlow taints the value of curr if one of its operands is tainted,
+// and the address of a store whose value is tainted
+func (p *TaintProblem) NormalFlow(curr ssa.Instruction, succ ssa.Instruction) ifds.FlowFunction[ssa.Value] {
+	return func(d ssa.Value) []ssa.Value {
+		if d == nil {
+			return nil
+		}
+		if store, ok := curr.(*ssa.Store); ok && store.Val == d {
+			return []ssa.Value{d, store.Addr}
+		}
+		if v, ok := curr.(ssa.Value); ok && uses(curr, d) {
+			return []ssa.Value{d, v}
+		}
+		return []ssa.Value{d}
+	}
+}
+
+// CallFlow maps tainted arguments to the parameters of callee
+func (p *TaintProblem) CallFlow(callSite ssa.Instruction, callee *ssa.Function) ifds.FlowFunction[ssa.Value] {
+	common := callSite.(ssa.CallInstruction).Common()
+	return func(d ssa.Value) []ssa.Value {
+		facts := make([]ssa.Value, 0)
+		for i, arg := range args(common) {
+			if arg == d && i < len(callee.Params) {
+				facts = append(facts, callee.Params[i])
+			}
+		}
+		return facts
+	}
+}
+
+// ReturnFlow taints the value of callSite if exit returns a tainted value
+func (p *TaintProblem) ReturnFlow(callSite ssa.Instruction, callee *ssa.Function, exit ssa.Instruction, returnSite ssa.Instruction) ifds.FlowFunction[ssa.Value] {
+	return func(d ssa.Value) []ssa.Value {
+		ret, ok := exit.(*ssa.Return)
+		call, isCall := callSite.(*ssa.Call)
+		if d == nil || !ok || !isCall {
+			return nil
+		}
+		for _, r := range ret.Results {
+			if r == d {
+				return []ssa.Value{call}
+			}
+		}
+		return nil
+	}
+}
+
+// CallToReturnFlow keeps the facts of the caller, taints the value of calls of Source,
+// and taints the value of calls without a body if one of their arguments is tainted
+func (p *TaintProblem) CallToReturnFlow(callSite ssa.Instruction, returnSite ssa.Instruction) ifds.FlowFunction[ssa.Value] {
+	call, isCall := callSite.(*ssa.Call)
+	external := len(p.icfg.CalleesOf(callSite)) == 0
+	return func(d ssa.Value) []ssa.Value {
+		if !isCall {
+			return []ssa.Value{d}
+		}
+		if d == nil {
+			if isCallTo(call.Common(), p.Source) {
+				return []ssa.Value{d, call}
+			}
+			return []ssa.Value{d}
+		}
+		if external && uses(call, d) {
+			return []ssa.Value{d, call}
+		}
+		return []ssa.Value{d}
+	}
+}
+
+// IsSink returns whether inst is a call of Sink
+func (p *TaintProblem) IsSink(inst ssa.Instruction) bool {
+	call, ok := inst.(ssa.CallInstruction)
+	return ok && isCallTo(call.Common(), p.Sink)
+}
+
+// uses returns whether v is an operand of inst
+func uses(inst ssa.Instruction, v ssa.Value) bool {
+	for _, op := range inst.Operands(nil) {
+		if *op == v {
+			return true
+		}
+	}
+	return false
+}
+
+// args returns the arguments of a call, with the receiver first for an invoke
+func args(common *ssa.CallCommon) []ssa.Value {
+	if common.IsInvoke() {
+		return append([]ssa.Value{common.Value}, common.Args...)
+	}
+	return common.Args
+}
+
+// isCallTo returns whether common statically calls a function named name
+func isCallTo(common *ssa.CallCommon, name string) bool {
+	callee := common.StaticCallee()
+	return callee != nil && callee.Name() == name
+}
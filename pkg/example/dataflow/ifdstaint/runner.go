@@ -0,0 +1,99 @@
+package ifdstaint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"sort"
+
+	"github.com/dnote/color"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/ifds"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Runner represents an IFDS taint analysis runner
+type Runner struct {
+	Src      string
+	Function string
+	Leaks    []*Leak // tainted arguments of sinks found by Run, in order of functions
+}
+
+// Leak represents a tainted value passed to a sink
+type Leak struct {
+	Function string // name of the function calling the sink
+	Value    string // name of the tainted argument
+	Call     string // SSA of the sink call
+}
+
+// NewRunner returns a *ifdstaint.Runner
+func NewRunner(src string, function string) *Runner {
+	runner := new(Runner)
+	runner.Src = src
+	runner.Function = function
+	return runner
+}
+
+// Run kick off the analysis
+func (r *Runner) Run() {
+	// Generate ast
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", r.Src, parser.Mode(0))
+	if err != nil {
+		log.Println(err)
+	}
+	files := []*ast.File{f}
+
+	// Build package
+	pkg := types.NewPackage("ifdstaintanalysis", "")
+	hello, _, err := ssautil.BuildPackage(
+		&types.Config{Importer: importer.Default()}, fset, pkg, files, ssa.SanityCheckFunctions)
+	if err != nil {
+		log.Println(err)
+	}
+
+	// Build supergraph
+	g, err := icfg.Build(hello.Prog, icfg.CHA)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Build problem
+	problem := NewTaintProblem(g, hello.Func(r.Function))
+
+	// Solve problem
+	solver := ifds.NewIFDSSolver[ssa.Value](problem)
+	solver.Solve()
+
+	// Collect and print tainted arguments of sinks
+	r.Leaks = make([]*Leak, 0)
+	funcs := make([]*ssa.Function, 0)
+	for fn := range ssautil.AllFunctions(hello.Prog) {
+		funcs = append(funcs, fn)
+	}
+	sort.Slice(funcs, func(i, j int) bool {
+		return funcs[i].String() < funcs[j].String()
+	})
+	for _, fn := range funcs {
+		for _, call := range g.CallsWithin(fn) {
+			if !problem.IsSink(call) {
+				continue
+			}
+			for _, arg := range args(call.(ssa.CallInstruction).Common()) {
+				if solver.Holds(call, arg) {
+					leak := &Leak{Function: fn.Name(), Value: arg.Name(), Call: call.String()}
+					r.Leaks = append(r.Leaks, leak)
+					color.Set(color.FgRed)
+					fmt.Printf("tainted value %v reaches sink in %v: %v\n", leak.Value, leak.Function, leak.Call)
+					color.Unset()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package ifdstaint
+
+import (
+	"slices"
+	"testing"
+)
+
+const src = `package main
+
+func source() string {
+	return "secret"
+}
+
+func sink(s string) {
+}
+
+func id(s string) string {
+	return s
+}
+
+func wrap(s string) string {
+	return "<" + id(s) + ">"
+}
+
+func clean(s string) string {
+	return "clean"
+}
+
+func leak(s string) {
+	sink(s)
+}
+
+func main() {
+	a := source()
+	sink(wrap(a))
+	sink(clean(a))
+	sink(id("constant"))
+	leak(a)
+	p := new(string)
+	*p = a
+	sink(*p)
+}`
+
+func TestRunner(t *testing.T) {
+	r := NewRunner(src, "main")
+	r.Run()
+	got := make([]string, 0)
+	for _, leak := range r.Leaks {
+		got = append(got, leak.Function+": "+leak.Call)
+	}
+	// id returns the constant to the call in main without the taint of the call in wrap,
+	// and the taint stored through p is loaded back
+	want := []string{"leak: sink(s)", "main: sink(t1)", "main: sink(t9)"}
+	if !slices.Equal(got, want) {
+		t.Errorf("leaks %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,320 @@
+package interval
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/typed"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Env represents the intervals of integer values at a unit,
+// a value missing from a reachable Env may hold any integer,
+// a nil Env means the unit is unreachable
+type Env map[string]Interval
+
+// IntervalAnalysis represents an integer interval analysis
+// it widens the flows at loop heads so that loops converge, and narrows them afterwards
+type IntervalAnalysis struct {
+	typed.BaseFlowAnalysis
+}
+
+// New creates an IntervalAnalysis
+func New(g *graph.UnitGraph) *IntervalAnalysis {
+	intervalAnalysis := new(IntervalAnalysis)
+	intervalAnalysis.BaseFlowAnalysis = *typed.NewBase(g)
+	return intervalAnalysis
+}
+
+// Bottom returns nil, the flow of unreachable units
+func (a *IntervalAnalysis) Bottom() Env {
+	return nil
+}
+
+// EntryFlow returns an empty Env, where parameters may hold any integer
+func (a *IntervalAnalysis) EntryFlow() Env {
+	return make(Env)
+}
+
+// Join merges y into x, a value defined on one side only keeps its interval
+// because in SSA form it cannot be used where the other side flows
+func (a *IntervalAnalysis) Join(x Env, y Env) Env {
+	if x == nil {
+		return a.Copy(y)
+	}
+	for k, v := range y {
+		if w, ok := x[k]; ok {
+			x[k] = w.Hull(v)
+		} else {
+			x[k] = v
+		}
+	}
+	return x
+}
+
+// Widen widens the intervals in prev by the intervals in next
+func (a *IntervalAnalysis) Widen(prev Env, next Env) Env {
+	return combine(prev, next, Interval.Widen)
+}
+
+// Narrow narrows the intervals in prev by the intervals in next
+func (a *IntervalAnalysis) Narrow(prev Env, next Env) Env {
+	return combine(prev, next, Interval.Narrow)
+}
+
+// combine returns a new Env where values in both prev and next are combined by f
+// and values only in next keep their intervals
+func combine(prev Env, next Env, f func(Interval, Interval) Interval) Env {
+	if prev == nil || next == nil {
+		return next
+	}
+	m := make(Env, len(next))
+	for k, v := range next {
+		if p, ok := prev[k]; ok {
+			m[k] = f(p, v)
+		} else {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// Equal returns whether two Envs have the same intervals
+func (a *IntervalAnalysis) Equal(x Env, y Env) bool {
+	if (x == nil) != (y == nil) || len(x) != len(y) {
+		return false
+	}
+	for k, v := range x {
+		if w, ok := y[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy returns a copy of an Env
+func (a *IntervalAnalysis) Copy(f Env) Env {
+	if f == nil {
+		return nil
+	}
+	m := make(Env, len(f))
+	for k, v := range f {
+		m[k] = v
+	}
+	return m
+}
+
+// FlowThrough calculates the intervals after unit
+func (a *IntervalAnalysis) FlowThrough(in Env, unit ssa.Instruction) Env {
+	if in == nil {
+		return nil
+	}
+	out := a.Copy(in)
+	if jump, ok := unit.(*ssa.Jump); ok {
+		passPhis(out, jump.Block(), jump.Block().Succs[0])
+		return out
+	}
+	v, ok := unit.(ssa.Value)
+	if !ok || !isInteger(v.Type()) {
+		return out
+	}
+
+	r := Top()
+	switch inst := unit.(type) {
+	case *ssa.BinOp:
+		x, y := lookup(in, inst.X), lookup(in, inst.Y)
+		switch {
+		case x.IsEmpty() || y.IsEmpty():
+			r = Interval{PosInf, NegInf}
+		case inst.Op == token.ADD:
+			r = x.Add(y)
+		case inst.Op == token.SUB:
+			r = x.Sub(y)
+		case inst.Op == token.MUL:
+			r = x.Mul(y)
+		}
+	case *ssa.UnOp:
+		if inst.Op == token.SUB {
+			r = lookup(in, inst.X).Neg()
+		}
+	case *ssa.Convert:
+		r = lookup(in, inst.X)
+	case *ssa.Phi:
+		// the edges are evaluated at the end of the predecessors, see passPhis
+		r = Interval{PosInf, NegInf}
+		if i, ok := in[v.Name()]; ok {
+			r = i
+		}
+	}
+	// a result out of the range of its type may wrap around
+	if !fits(v.Type(), r) {
+		r = Top()
+	}
+	out[v.Name()] = r
+	return out
+}
+
+// FlowThroughBranch refines the operands of a comparison on the edge from an If to succ,
+// an edge whose condition can never hold gets an unreachable Env
+func (a *IntervalAnalysis) FlowThroughBranch(in Env, inst *ssa.If, succ ssa.Instruction) Env {
+	out := a.refineBranch(in, inst, succ)
+	if out != nil {
+		passPhis(out, inst.Block(), succ.Block())
+	}
+	return out
+}
+
+// refineBranch refines the operands of the condition of inst on the edge to succ
+func (a *IntervalAnalysis) refineBranch(in Env, inst *ssa.If, succ ssa.Instruction) Env {
+	out := a.Copy(in)
+	cond, ok := inst.Cond.(*ssa.BinOp)
+	if in == nil || !ok || !isInteger(cond.X.Type()) {
+		return out
+	}
+	then, els := inst.Block().Succs[0], inst.Block().Succs[1]
+	if then == els {
+		return out
+	}
+	op := cond.Op
+	if succ.Block() == els {
+		op = negate(op)
+	}
+
+	x, y := lookup(in, cond.X), lookup(in, cond.Y)
+	switch op {
+	case token.LSS:
+		x, y = x.Meet(Interval{NegInf, addBound(y.Hi, -1)}), y.Meet(Interval{addBound(x.Lo, 1), PosInf})
+	case token.LEQ:
+		x, y = x.Meet(Interval{NegInf, y.Hi}), y.Meet(Interval{x.Lo, PosInf})
+	case token.GTR:
+		x, y = x.Meet(Interval{addBound(y.Lo, 1), PosInf}), y.Meet(Interval{NegInf, addBound(x.Hi, -1)})
+	case token.GEQ:
+		x, y = x.Meet(Interval{y.Lo, PosInf}), y.Meet(Interval{NegInf, x.Hi})
+	case token.EQL:
+		x = x.Meet(y)
+		y = x
+	case token.NEQ:
+		x, y = exclude(x, y), exclude(y, x)
+	default:
+		return out
+	}
+	if x.IsEmpty() || y.IsEmpty() {
+		return nil
+	}
+	refine(out, cond.X, x)
+	refine(out, cond.Y, y)
+	return out
+}
+
+// passPhis evaluates the edges from b of the phis in succ with the flow at the end of b,
+// so that a phi gets the hull of the intervals its edges have in their own predecessors
+func passPhis(flow Env, b *ssa.BasicBlock, succ *ssa.BasicBlock) {
+	for i, pred := range succ.Preds {
+		if pred != b {
+			continue
+		}
+		for _, inst := range succ.Instrs {
+			phi, ok := inst.(*ssa.Phi)
+			if !ok {
+				break
+			}
+			if isInteger(phi.Type()) {
+				flow[phi.Name()] = lookup(flow, phi.Edges[i])
+			}
+		}
+		return
+	}
+}
+
+// lookup returns the interval of v in flow
+func lookup(flow Env, v ssa.Value) Interval {
+	if c, ok := v.(*ssa.Const); ok {
+		if c.Value != nil && c.Value.Kind() == constant.Int {
+			if i, exact := constant.Int64Val(c.Value); exact {
+				return Const(i)
+			}
+		}
+		return Top()
+	}
+	if i, ok := flow[v.Name()]; ok {
+		return i
+	}
+	return Top()
+}
+
+// refine records a refined interval of v in flow, constants are left alone
+func refine(flow Env, v ssa.Value, i Interval) {
+	if _, ok := v.(*ssa.Const); !ok {
+		flow[v.Name()] = i
+	}
+}
+
+// exclude removes the value of y from the bounds of x if y is a constant
+func exclude(x Interval, y Interval) Interval {
+	if !y.IsConst() {
+		return x
+	}
+	if x.Lo == y.Lo {
+		x.Lo = addBound(x.Lo, 1)
+	}
+	if x.Hi == y.Lo {
+		x.Hi = addBound(x.Hi, -1)
+	}
+	return x
+}
+
+// negate returns the comparison which holds when op does not
+func negate(op token.Token) token.Token {
+	switch op {
+	case token.LSS:
+		return token.GEQ
+	case token.LEQ:
+		return token.GTR
+	case token.GTR:
+		return token.LEQ
+	case token.GEQ:
+		return token.LSS
+	case token.EQL:
+		return token.NEQ
+	case token.NEQ:
+		return token.EQL
+	}
+	return op
+}
+
+// isInteger returns whether t is an integer type
+func isInteger(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+// fits returns whether every integer in i can be held by type t
+func fits(t types.Type, i Interval) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok || i.IsEmpty() {
+		return true
+	}
+	var lo, hi int64
+	switch b.Kind() {
+	case types.Int8:
+		lo, hi = math.MinInt8, math.MaxInt8
+	case types.Int16:
+		lo, hi = math.MinInt16, math.MaxInt16
+	case types.Int32:
+		lo, hi = math.MinInt32, math.MaxInt32
+	case types.Uint8:
+		lo, hi = 0, math.MaxUint8
+	case types.Uint16:
+		lo, hi = 0, math.MaxUint16
+	case types.Uint32:
+		lo, hi = 0, math.MaxUint32
+	case types.Uint, types.Uint64, types.Uintptr:
+		lo, hi = 0, PosInf
+	default:
+		return true
+	}
+	return i.Lo >= lo && i.Hi <= hi
+}
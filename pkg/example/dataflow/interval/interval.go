@@ -0,0 +1,183 @@
+package interval
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// NegInf represents the lower bound of an interval unbounded below
+	NegInf int64 = math.MinInt64
+	// PosInf represents the upper bound of an interval unbounded above
+	PosInf int64 = math.MaxInt64
+)
+
+// Interval represents the integers in [Lo, Hi], it is empty if Lo > Hi
+type Interval struct {
+	Lo int64
+	Hi int64
+}
+
+// Top returns the interval of all integers
+func Top() Interval {
+	return Interval{NegInf, PosInf}
+}
+
+// Const returns the interval containing only c
+func Const(c int64) Interval {
+	return Interval{c, c}
+}
+
+// IsEmpty returns whether the interval contains no integer
+func (i Interval) IsEmpty() bool {
+	return i.Lo > i.Hi
+}
+
+// IsConst returns whether the interval contains exactly one integer
+func (i Interval) IsConst() bool {
+	return i.Lo == i.Hi
+}
+
+// Hull returns the smallest interval containing both i and j
+func (i Interval) Hull(j Interval) Interval {
+	if i.IsEmpty() {
+		return j
+	}
+	if j.IsEmpty() {
+		return i
+	}
+	return Interval{min(i.Lo, j.Lo), max(i.Hi, j.Hi)}
+}
+
+// Meet returns the intersection of i and j
+func (i Interval) Meet(j Interval) Interval {
+	return Interval{max(i.Lo, j.Lo), min(i.Hi, j.Hi)}
+}
+
+// Widen returns i widened by j, a bound of i that j exceeds goes to infinity
+func (i Interval) Widen(j Interval) Interval {
+	if i.IsEmpty() {
+		return j
+	}
+	if j.IsEmpty() {
+		return i
+	}
+	w := i
+	if j.Lo < i.Lo {
+		w.Lo = NegInf
+	}
+	if j.Hi > i.Hi {
+		w.Hi = PosInf
+	}
+	return w
+}
+
+// Narrow returns i narrowed by j, an infinite bound of i is replaced by the bound of j
+func (i Interval) Narrow(j Interval) Interval {
+	if i.IsEmpty() || j.IsEmpty() {
+		return j
+	}
+	n := i
+	if i.Lo == NegInf {
+		n.Lo = j.Lo
+	}
+	if i.Hi == PosInf {
+		n.Hi = j.Hi
+	}
+	return n
+}
+
+// Add returns the interval of x + y for x in i and y in j
+func (i Interval) Add(j Interval) Interval {
+	return Interval{addBound(i.Lo, j.Lo), addBound(i.Hi, j.Hi)}
+}
+
+// Sub returns the interval of x - y for x in i and y in j
+func (i Interval) Sub(j Interval) Interval {
+	return i.Add(j.Neg())
+}
+
+// Neg returns the interval of -x for x in i
+func (i Interval) Neg() Interval {
+	return Interval{negBound(i.Hi), negBound(i.Lo)}
+}
+
+// Mul returns the interval of x * y for x in i and y in j
+func (i Interval) Mul(j Interval) Interval {
+	a := mulBound(i.Lo, j.Lo)
+	b := mulBound(i.Lo, j.Hi)
+	c := mulBound(i.Hi, j.Lo)
+	d := mulBound(i.Hi, j.Hi)
+	return Interval{min(a, b, c, d), max(a, b, c, d)}
+}
+
+// String returns the interval in the form [Lo, Hi]
+func (i Interval) String() string {
+	if i.IsEmpty() {
+		return "empty"
+	}
+	return fmt.Sprintf("[%v, %v]", boundString(i.Lo), boundString(i.Hi))
+}
+
+func boundString(b int64) string {
+	switch b {
+	case NegInf:
+		return "-inf"
+	case PosInf:
+		return "+inf"
+	default:
+		return fmt.Sprint(b)
+	}
+}
+
+// addBound adds two bounds, going to infinity instead of overflowing
+func addBound(a int64, b int64) int64 {
+	if a == NegInf || b == NegInf {
+		return NegInf
+	}
+	if a == PosInf || b == PosInf {
+		return PosInf
+	}
+	s := a + b
+	if b > 0 && s < a {
+		return PosInf
+	}
+	if b < 0 && s > a {
+		return NegInf
+	}
+	return s
+}
+
+// negBound negates a bound, infinities swap their signs
+func negBound(a int64) int64 {
+	switch a {
+	case NegInf:
+		return PosInf
+	case PosInf:
+		return NegInf
+	default:
+		return -a
+	}
+}
+
+// mulBound multiplies two bounds, going to infinity instead of overflowing
+func mulBound(a int64, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	negative := (a < 0) != (b < 0)
+	if a == NegInf || a == PosInf || b == NegInf || b == PosInf {
+		if negative {
+			return NegInf
+		}
+		return PosInf
+	}
+	p := a * b
+	if p/b != a {
+		if negative {
+			return NegInf
+		}
+		return PosInf
+	}
+	return p
+}
@@ -0,0 +1,90 @@
+package interval
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/dnote/color"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Runner represents a interval analysis runner
+type Runner struct {
+	Src      string
+	Function string
+}
+
+// NewRunner returns a *interval.Runner
+func NewRunner(src string, function string) *Runner {
+	runner := new(Runner)
+	runner.Src = src
+	runner.Function = function
+	return runner
+}
+
+// Run kick off the analysis
+func (r *Runner) Run() {
+	// Generate ast
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", r.Src, parser.Mode(0))
+	if err != nil {
+		log.Println(err)
+	}
+	files := []*ast.File{f}
+
+	// Build package
+	pkg := types.NewPackage("intervalanalysis", "")
+	hello, _, err := ssautil.BuildPackage(
+		&types.Config{Importer: importer.Default()}, fset, pkg, files, ssa.SanityCheckFunctions)
+	if err != nil {
+		log.Println(err)
+	}
+	fn := hello.Func(r.Function)
+	fn.WriteTo(os.Stdout)
+
+	// Build graph
+	graph := graph.New(fn)
+
+	// Build analysis
+	analysis := New(graph)
+
+	// Solve analysis
+	result := solver.SolveTyped(analysis, true)
+
+	// Print intervals after every instruction
+	for _, b := range fn.Blocks {
+		for _, inst := range b.Instrs {
+			color.Set(color.FgGreen)
+			fmt.Println("intervals after instruction: " + inst.String())
+			color.Unset()
+			fmt.Println(format(result.Out(inst)))
+			fmt.Println()
+		}
+	}
+}
+
+func format(env Env) string {
+	if env == nil {
+		return "unreachable"
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	s := ""
+	for _, k := range keys {
+		s += fmt.Sprintf("%v=%v ", k, env[k])
+	}
+	return s
+}
@@ -0,0 +1,124 @@
+package liveness
+
+import (
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/typed"
+	"golang.org/x/tools/go/ssa"
+)
+
+// LiveSet represents a set of live variable names
+type LiveSet map[string]bool
+
+// LivenessAnalysis represents a live variables analysis, it is a backward analysis
+type LivenessAnalysis struct {
+	typed.BaseFlowAnalysis
+}
+
+// New creates a LivenessAnalysis
+func New(g *graph.UnitGraph) *LivenessAnalysis {
+	livenessAnalysis := new(LivenessAnalysis)
+	livenessAnalysis.BaseFlowAnalysis = *typed.NewBase(g)
+	return livenessAnalysis
+}
+
+// IsForward returns false because liveness flows from uses back to definitions
+func (a *LivenessAnalysis) IsForward() bool {
+	return false
+}
+
+// Bottom returns an empty set
+func (a *LivenessAnalysis) Bottom() LiveSet {
+	return make(LiveSet)
+}
+
+// EntryFlow returns the flow at exits, where nothing is live
+func (a *LivenessAnalysis) EntryFlow() LiveSet {
+	return a.Bottom()
+}
+
+// Join unions y into x
+func (a *LivenessAnalysis) Join(x LiveSet, y LiveSet) LiveSet {
+	for k := range y {
+		x[k] = true
+	}
+	return x
+}
+
+// Equal returns whether two sets have the same variables
+func (a *LivenessAnalysis) Equal(x LiveSet, y LiveSet) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for k := range x {
+		if !y[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy returns a copy of a set
+func (a *LivenessAnalysis) Copy(f LiveSet) LiveSet {
+	m := make(LiveSet, len(f))
+	for k := range f {
+		m[k] = true
+	}
+	return m
+}
+
+// FlowThrough calculates the variables live before unit from the variables live after it
+func (a *LivenessAnalysis) FlowThrough(in LiveSet, unit ssa.Instruction) LiveSet {
+	out := a.Copy(in)
+	// kill the definition
+	if v, ok := unit.(ssa.Value); ok {
+		delete(out, v.Name())
+	}
+	// phi operands are used at the end of predecessors, see below
+	if _, ok := unit.(*ssa.Phi); ok {
+		return out
+	}
+	// gen the uses
+	for _, op := range unit.Operands(nil) {
+		if *op != nil && isVariable(*op) {
+			out[(*op).Name()] = true
+		}
+	}
+	// the last unit of a block uses the phi edges of its successors from this block
+	b := unit.Block()
+	if b != nil && len(b.Instrs) != 0 && b.Instrs[len(b.Instrs)-1] == unit {
+		for _, succ := range b.Succs {
+			i := predIndex(succ, b)
+			for _, inst := range succ.Instrs {
+				phi, ok := inst.(*ssa.Phi)
+				if !ok {
+					break
+				}
+				if isVariable(phi.Edges[i]) {
+					out[phi.Edges[i].Name()] = true
+				}
+			}
+		}
+	}
+	return out
+}
+
+// isVariable returns whether a value is held in a variable of the function
+func isVariable(v ssa.Value) bool {
+	switch v.(type) {
+	case *ssa.Parameter, *ssa.FreeVar:
+		return true
+	case ssa.Instruction:
+		return true
+	}
+	return false
+}
+
+// predIndex returns the index of pred in b.Preds
+func predIndex(b *ssa.BasicBlock, pred *ssa.BasicBlock) int {
+	for i, p := range b.Preds {
+		if p == pred {
+			return i
+		}
+	}
+	return -1
+}
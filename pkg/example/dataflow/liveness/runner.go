@@ -0,0 +1,85 @@
+package liveness
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/dnote/color"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Runner represents a liveness analysis runner
+type Runner struct {
+	Src      string
+	Function string
+}
+
+// NewRunner returns a *liveness.Runner
+func NewRunner(src string, function string) *Runner {
+	runner := new(Runner)
+	runner.Src = src
+	runner.Function = function
+	return runner
+}
+
+// Run kick off the analysis
+func (r *Runner) Run() {
+	// Generate ast
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", r.Src, parser.Mode(0))
+	if err != nil {
+		log.Println(err)
+	}
+	files := []*ast.File{f}
+
+	// Build package
+	pkg := types.NewPackage("livenessanalysis", "")
+	hello, _, err := ssautil.BuildPackage(
+		&types.Config{Importer: importer.Default()}, fset, pkg, files, ssa.SanityCheckFunctions)
+	if err != nil {
+		log.Println(err)
+	}
+	fn := hello.Func(r.Function)
+	fn.WriteTo(os.Stdout)
+
+	// Build graph
+	graph := graph.New(fn)
+
+	// Build analysis
+	analysis := New(graph)
+
+	// Solve analysis
+	result := solver.SolveTyped(analysis, true)
+
+	// Print live variables around every instruction
+	for _, b := range fn.Blocks {
+		for _, inst := range b.Instrs {
+			color.Set(color.FgGreen)
+			fmt.Println("live variables for instruction: " + inst.String())
+			color.Unset()
+			// in a backward analysis, In is the flow after the instruction
+			fmt.Println("before:", names(result.Out(inst)))
+			fmt.Println("after: ", names(result.In(inst)))
+			fmt.Println()
+		}
+	}
+}
+
+func names(s LiveSet) []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,66 @@
+package taint
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/andersen"
+	"golang.org/x/tools/go/ssa"
+)
+
+// aliases returns the values of the analyzed function which may point to the memory addr points to,
+// nil if taint does not follow aliases
+func (s *TaintSwitcher) aliases(addr ssa.Value) []andersen.Alias {
+	if s.taintAnalysis.config.Aliases == nil {
+		return nil
+	}
+	return s.taintAnalysis.config.Aliases.Aliases(s.taintAnalysis.Graph.Func, addr)
+}
+
+// storeAliases passes the taint stored through addr to its aliases,
+// an alias pointing into the memory of addr gets the taint of the field it points to,
+// and an alias of memory holding the memory of addr gets it in the field addr points to
+func (s *TaintSwitcher) storeAliases(addr ssa.Value) {
+	for _, alias := range s.aliases(addr) {
+		if alias.Outer {
+			s.writeField(alias.Value.Name(), alias.Path, addr.Name())
+		} else {
+			s.readField(alias.Value.Name(), addr.Name(), alias.Path)
+		}
+	}
+}
+
+// loadAliases passes the taint stored through the aliases of addr to dst, a value loaded from addr
+func (s *TaintSwitcher) loadAliases(dst string, addr ssa.Value) {
+	for _, alias := range s.aliases(addr) {
+		if alias.Outer {
+			s.readField(dst, alias.Value.Name(), alias.Path)
+		} else {
+			s.writeField(dst, alias.Path, alias.Value.Name())
+		}
+	}
+}
+
+// writeAliases writes the aliases of the addresses f stores to and loads from, which a summary of f depends on
+func writeAliases(w io.Writer, aliases *andersen.Result, f *ssa.Function) {
+	for _, b := range f.Blocks {
+		for _, inst := range b.Instrs {
+			var addr ssa.Value
+			switch inst := inst.(type) {
+			case *ssa.Store:
+				addr = inst.Addr
+			case *ssa.UnOp:
+				if inst.Op != token.MUL {
+					continue
+				}
+				addr = inst.X
+			default:
+				continue
+			}
+			for _, alias := range aliases.Aliases(f, addr) {
+				fmt.Fprintln(w, "alias", addr.Name(), alias.Value.Name(), alias.Path, alias.Outer)
+			}
+		}
+	}
+}
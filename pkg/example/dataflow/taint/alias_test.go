@@ -0,0 +1,37 @@
+package taint
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAliasAnalysis(t *testing.T) {
+	for _, tc := range []struct {
+		alias bool
+		depth int
+		want  []string
+	}{
+		// taint stays in the registers of the pointers written through
+		{false, 0, []string{}},
+		{true, 0, []string{
+			"alias.ViaCall -> lib.Query @ alias/alias.go:21:11",
+			"alias.ViaField -> lib.Exec @ alias/alias.go:37:10",
+			"alias.ViaField -> lib.Render @ alias/alias.go:38:12",
+			"alias.ViaGlobal -> lib.Query @ alias/alias.go:29:11",
+		}},
+		// the alias of &b.x taints field x of b only
+		{true, 2, []string{
+			"alias.ViaCall -> lib.Query @ alias/alias.go:21:11",
+			"alias.ViaField -> lib.Exec @ alias/alias.go:37:10",
+			"alias.ViaGlobal -> lib.Query @ alias/alias.go:29:11",
+		}},
+	} {
+		r := newGoldenRunner(t, "alias")
+		r.UseAliasAnalysis = tc.alias
+		r.FieldDepth = tc.depth
+		runGolden(t, r)
+		if got := findingsOf(r); !slices.Equal(got, tc.want) {
+			t.Errorf("alias %v depth %d: findings %q, want %q", tc.alias, tc.depth, got, tc.want)
+		}
+	}
+}
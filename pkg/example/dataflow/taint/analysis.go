@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/types"
 	"os"
+	"time"
 
 	"github.com/zeroy0410/goot/pkg/dataflow/golang/switcher"
 	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
@@ -24,7 +25,7 @@ type TaintAnalysis struct {
 // Run 启动一个函数的污点分析
 func Run(f *ssa.Function, c *TaintConfig) {
 	// 如果已经在其他地方记录在 passThroughContainer 中，则跳过
-	if _, ok := (*c.PassThroughContainer)[f.String()]; ok {
+	if _, ok := c.getPassThrough(f.String()); ok {
 		return
 	}
 
@@ -45,15 +46,32 @@ func Run(f *ssa.Function, c *TaintConfig) {
 
 // doRun 执行函数的污点分析
 func doRun(f *ssa.Function, c *TaintConfig) {
+	// 整个分析的上下文结束或内存超过上限时不再求解，用空的 passThrough 代替
+	if reason := c.exhausted(); reason != solver.Fixpoint {
+		recordNotConverged(f, c, reason, 0, 0)
+		initNull(f, c)
+		return
+	}
+
 	// 将函数标记为已访问以防止递归
 	recordCall(f, c)
 
 	// 创建一个新的分析
-	g := graph.New(f)
+	g := graph.NewWithOptions(f, graph.Options{Exceptional: c.Exceptional})
 	a := New(g, c)
 
 	// 在调试模式下解决分析
-	solver.Solve(a, c.Debug)
+	s := new(solver.Solver)
+	s.Analysis = a
+	s.Debug = c.Debug
+	s.Worklist = c.Worklist
+	s.Context = c.Context
+	s.Budget = c.Budget
+	start := time.Now()
+	result := s.DoAnalysis()
+	if !result.Converged() {
+		recordNotConverged(f, c, result.StopReason(), result.Iterations(), time.Since(start))
+	}
 }
 
 // recordCall 记录调用历史以防止递归
@@ -75,8 +93,10 @@ func initNull(f *ssa.Function, c *TaintConfig) {
 	param := f.Signature.Params().Len()
 	passThrough := NewPassThrough(names, recv, result, param)
 	passThroughCache := passThrough.ToCache()
-	(*c.PassThroughContainer)[f.String()] = passThroughCache
-	fmt.Println("end analysis for:", f.String(), ", result: ", passThroughCache)
+	c.setPassThrough(f.String(), passThroughCache)
+	if c.Debug {
+		fmt.Println("end analysis for:", f.String(), ", result: ", passThroughCache)
+	}
 }
 
 // needNull 判断函数是否需要初始化为空
@@ -147,7 +167,10 @@ func (a *TaintAnalysis) NewInitalFlow() *map[any]any {
 
 // Computations 限制流图上的计算次数
 func (a *TaintAnalysis) Computations() int {
-	return 3000
+	if a.config.MaxComputations > 0 {
+		return a.config.MaxComputations
+	}
+	return DefaultMaxComputations
 }
 
 // FlowThrougth 基于 inMap 和 unit 计算 outMap
@@ -156,6 +179,13 @@ func (a *TaintAnalysis) FlowThrougth(inMap *map[any]any, unit ssa.Instruction, o
 	a.apply(inMap, unit, outMap)
 }
 
+// FlowThroughBranch 基于 inMap 计算从 If 到 succ 的边上的 outMap
+// 条件是净化函数的调用时，它返回 true 的分支上的参数被净化
+func (a *TaintAnalysis) FlowThroughBranch(inMap *map[any]any, inst *ssa.If, succ ssa.Instruction, outMap *map[any]any) {
+	a.Copy(inMap, outMap)
+	a.sanitizeBranch(inst, succ, outMap)
+}
+
 // apply 调用 switcher.Apply
 func (a *TaintAnalysis) apply(inMap *map[any]any, inst ssa.Instruction, outMap *map[any]any) {
 	a.taintSwitcher.inMap = inMap
@@ -192,10 +222,12 @@ func (a *TaintAnalysis) End(universe []*entry.Entry) {
 
 	// 保存 passThrough 到 passThroughContainer
 	passThroughCache := a.passThrough.ToCache()
-	(*c.PassThroughContainer)[f.String()] = passThroughCache
+	c.setPassThrough(f.String(), passThroughCache)
 
 	// 弹出调用栈
 	c.CallStack.Remove(c.CallStack.Back())
 
-	fmt.Println("finish analysis for: "+f.String()+", result: ", passThroughCache)
+	if c.Debug {
+		fmt.Println("finish analysis for: "+f.String()+", result: ", passThroughCache)
+	}
 }
@@ -0,0 +1,193 @@
+package taint
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/andersen"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/worklist"
+	"github.com/zeroy0410/goot/pkg/example/dataflow/taint/rule"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Analyzer runs taint analysis on a package with a DummyRuler, the -module flag sets its module names
+// and the -rules flag replaces it with rule packs
+var Analyzer = NewAnalyzer(nil)
+
+// PassThroughFact represents the passthrough of a function exported to the packages importing it
+type PassThroughFact struct {
+	PassThrough *PassThroughCache
+}
+
+// AFact marks PassThroughFact as an analysis.Fact
+func (*PassThroughFact) AFact() {}
+
+// String returns the passthrough in json
+func (f *PassThroughFact) String() string {
+	res, _ := json.Marshal(f.PassThrough)
+	return "passthrough " + string(res)
+}
+
+// AnalyzerResult represents the result of the taint analyzer on a package
+type AnalyzerResult struct {
+	PassThrough map[string]*PassThroughCache // passthroughs of the functions of the package
+	Graph       *TaintGraph
+}
+
+// NewAnalyzer returns an *analysis.Analyzer running taint analysis on every package
+// passthroughs of functions are exported as PassThroughFacts, so callers in other packages use them,
+// and every taint edge to a sink is reported as a diagnostic at its call
+// a nil ruler means the rule packs in the -rules flag, or a DummyRuler if there are none,
+// of the module names in the -module flag
+func NewAnalyzer(ruler rule.Ruler) *analysis.Analyzer {
+	a := &analysis.Analyzer{
+		Name:       "taint",
+		Doc:        "report taint flowing from parameters of functions to sinks",
+		Requires:   []*analysis.Analyzer{buildssa.Analyzer},
+		ResultType: reflect.TypeOf(new(AnalyzerResult)),
+		FactTypes:  []analysis.Fact{new(PassThroughFact)},
+	}
+	var module string
+	var rules string
+	var exceptional bool
+	var fieldDepth int
+	var alias bool
+	a.Flags.StringVar(&module, "module", "", "comma separated module names, functions of them are intra nodes, empty means all")
+	a.Flags.StringVar(&rules, "rules", "", "comma separated yaml or json rule packs of sources and sinks")
+	a.Flags.BoolVar(&exceptional, "exceptional", false, "add panic edges and run deferred calls at function exits")
+	a.Flags.IntVar(&fieldDepth, "field-depth", 0, "k-limit of access paths tracking taint of fields, 0 means a tainted field taints the whole value")
+	a.Flags.BoolVar(&alias, "alias", false, "pass taint stored or loaded through a pointer to the other pointers to the same memory")
+	a.Run = func(pass *analysis.Pass) (any, error) {
+		r := ruler
+		if r == nil && rules != "" {
+			pack, err := NewPackRuler(strings.Split(module, ","), strings.Split(rules, ",")...)
+			if err != nil {
+				return nil, err
+			}
+			r = pack
+		} else if r == nil {
+			r = NewDummyRuler(strings.Split(module, ",")...)
+		}
+		return runPass(pass, r, exceptional, fieldDepth, alias)
+	}
+	return a
+}
+
+// runPass analyzes the source functions of a package, imports the passthroughs of its dependencies
+// from facts, and exports the passthroughs of its functions
+// aliases of pointers are only found among the functions of the package
+func runPass(pass *analysis.Pass, ruler rule.Ruler, exceptional bool, fieldDepth int, alias bool) (*AnalyzerResult, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	funcs := make(map[*ssa.Function]bool)
+	for _, f := range ssaInfo.SrcFuncs {
+		funcs[f] = true
+	}
+
+	passThroughContainer := make(map[string]*PassThroughCache)
+	for _, fact := range pass.AllObjectFacts() {
+		p, ok := fact.Fact.(*PassThroughFact)
+		obj, ok2 := fact.Object.(*types.Func)
+		if ok && ok2 {
+			passThroughContainer[funcName(obj)] = p.PassThrough
+		}
+	}
+
+	// 被调用的其他包的函数也需要节点，这样才能判断边是否指向 sink
+	nodes := make(map[*ssa.Function]bool)
+	for f := range funcs {
+		nodes[f] = true
+		for _, b := range f.Blocks {
+			for _, inst := range b.Instrs {
+				for _, op := range inst.Operands(nil) {
+					if g, ok := (*op).(*ssa.Function); ok {
+						nodes[g] = true
+					}
+				}
+			}
+		}
+	}
+	taintGraph := NewTaintGraph(&nodes, ruler)
+
+	interfaceHierarchy := icfg.NewInterfaceHierarchy(&funcs)
+	var aliases *andersen.Result
+	if alias {
+		aliases = andersen.Analyze(funcs, interfaceHierarchy.CallGraph(funcs))
+	}
+
+	initMap := make(map[string]*ssa.Function)
+	history := make(map[string]bool)
+	c := &TaintConfig{PassThroughContainer: &passThroughContainer,
+		InitMap:            &initMap,
+		History:            &history,
+		CallStack:          list.New().Init(),
+		InterfaceHierarchy: interfaceHierarchy,
+		TaintGraph:         taintGraph,
+		Ruler:              ruler,
+		Worklist:           worklist.FIFO,
+		Exceptional:        exceptional,
+		FieldDepth:         fieldDepth,
+		Aliases:            aliases,
+		MaxComputations:    DefaultMaxComputations}
+
+	sorted := sortedFuncs(funcs)
+	for _, f := range sorted {
+		if f.Name() == "init" {
+			Run(f, c)
+		}
+	}
+	for _, f := range sorted {
+		Run(f, c)
+	}
+
+	result := &AnalyzerResult{PassThrough: make(map[string]*PassThroughCache), Graph: taintGraph}
+	for _, f := range sorted {
+		p, ok := passThroughContainer[f.String()]
+		if !ok {
+			continue
+		}
+		result.PassThrough[f.String()] = p
+		if obj, ok := f.Object().(*types.Func); ok && obj.Pkg() == pass.Pkg && f.Synthetic == "" {
+			pass.ExportObjectFact(obj, &PassThroughFact{PassThrough: p})
+		}
+	}
+
+	byName := make(map[string]*ssa.Function, len(sorted))
+	for _, f := range sorted {
+		byName[f.String()] = f
+	}
+	keys := make([]string, 0, len(*taintGraph.Edges))
+	for key := range *taintGraph.Edges {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		edge := (*taintGraph.Edges)[key]
+		f, ok := byName[edge.From]
+		if !edge.ToIsSink || !ok {
+			continue
+		}
+		// 报告在调用的位置，调用和函数在同一个文件中
+		pos := f.Pos()
+		if file := pass.Fset.File(pos); file != nil && file.Name() == edge.Position.Filename {
+			pos = file.Pos(edge.Position.Offset)
+		}
+		pass.Reportf(pos, "taint from position %d of %s reaches position %d of sink %s", edge.FromIndex, edge.From, edge.ToIndex, edge.To)
+	}
+	return result, nil
+}
+
+// funcName returns the name of the *ssa.Function of a func object
+func funcName(obj *types.Func) string {
+	if recv := obj.Type().(*types.Signature).Recv(); recv != nil {
+		return fmt.Sprintf("(%s).%s", types.TypeString(recv.Type(), nil), obj.Name())
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
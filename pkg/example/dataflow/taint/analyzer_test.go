@@ -0,0 +1,25 @@
+package taint
+
+import (
+	"testing"
+
+	"github.com/zeroy0410/goot/pkg/example/dataflow/taint/rule"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+const analyzerRules = `
+modules: [passthru, handler]
+sources:
+  - params: ["*lib.Req"]
+sinks:
+  - function: lib.Exec
+`
+
+func TestAnalyzer(t *testing.T) {
+	pack, err := rule.ParsePack([]byte(analyzerRules), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// handler only sees the passthroughs of passthru through facts
+	analysistest.Run(t, analysistest.TestData(), NewAnalyzer(pack), "passthru", "handler")
+}
@@ -0,0 +1,271 @@
+package taint
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/zeroy0410/goot/pkg/example/dataflow/taint/rule"
+	"golang.org/x/tools/go/ssa"
+)
+
+// cacheVersion is hashed into every key, change it when the analysis or the entry format changes
+const cacheVersion = "goot-taint-summary-v6"
+
+// summaryCache represents a content-addressed cache of component summaries in a directory
+// the key of a component hashes its functions' SSA, the rule properties of their nodes, the digest of the rules,
+// the options of the analysis and the summaries of the functions it depends on,
+// so a change of a callee's summary invalidates its callers along reverse call graph edges,
+// while a change that keeps the summary keeps the callers cached
+type summaryCache struct {
+	dir    string
+	debug  bool
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// cacheEntry represents the writes of a component stored in the cache
+type cacheEntry struct {
+	PassThrough []cachedPassThrough
+	Edges       []cachedEdge
+}
+
+// cachedPassThrough represents a passthrough of a function in a cacheEntry
+type cachedPassThrough struct {
+	Function    string
+	PassThrough *PassThroughCache
+}
+
+// cachedEdge represents a taint edge in a cacheEntry
+type cachedEdge struct {
+	Key  string
+	Key2 string
+	Edge *Edge
+	Node *cachedNode `json:",omitempty"`
+}
+
+// cachedNode represents the prototype of a target node in a cacheEntry
+type cachedNode struct {
+	Canonical   string
+	Index       int
+	IsSignature bool
+	IsMethod    bool
+	IsStatic    bool
+	Position    token.Position
+}
+
+// newSummaryCache returns a summaryCache in dir, creating dir if needed
+func newSummaryCache(dir string, debug bool) (*summaryCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	cache := new(summaryCache)
+	cache.dir = dir
+	cache.debug = debug
+	return cache, nil
+}
+
+// key returns the hex key of a component, the summaries of its dependencies are read from the task
+func (s *summaryCache) key(task *TaintConfig, scc *component) string {
+	h := sha256.New()
+	fmt.Fprintln(h, cacheVersion)
+	fmt.Fprintln(h, task.Exceptional, task.PassBack, task.PassThroughOnly, task.UsePointerAnalysis, task.FieldDepth, task.Aliases != nil)
+	if task.UsePointerAnalysis {
+		fmt.Fprintln(h, "callgraph", task.CallGraphAlgorithm)
+	}
+	// 规则还决定了被调用者是否是净化函数以及下沉节点的种类，它们不在本组件的节点上
+	fmt.Fprintln(h, "rules", rulerDigest(task.Ruler))
+	for _, f := range scc.Funcs {
+		fmt.Fprintln(h, "func", f.String())
+		writeFunc(h, f)
+		// 别名来自整个程序，函数不变时也可能改变
+		if task.Aliases != nil {
+			writeAliases(h, task.Aliases, f)
+		}
+		// 被调用者是否是净化函数决定了摘要和边的 Sanitized
+		writeCallees(h, task, f)
+		// 规则决定了节点是否在模块内，从而决定记录哪些边
+		for i := 0; i <= len(f.Params); i++ {
+			if node, ok := (*task.TaintGraph.Nodes)[f.String()+"#"+strconv.Itoa(i)]; ok {
+				fmt.Fprintln(h, "node", i, node.IsIntra, node.IsSource, node.IsSink)
+			}
+		}
+	}
+	for _, f := range scc.Deps {
+		fmt.Fprintln(h, "dep", f.String())
+		if p, ok := task.getPassThrough(f.String()); ok {
+			res, _ := json.Marshal(p)
+			h.Write(res)
+		}
+		fmt.Fprintln(h)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// rulerDigest returns the digest of the rules of a rule.DigestRuler, or the type of other rulers
+func rulerDigest(ruler rule.Ruler) string {
+	if digestRuler, ok := ruler.(rule.DigestRuler); ok {
+		return digestRuler.Digest()
+	}
+	return fmt.Sprintf("%T", ruler)
+}
+
+// writeCallees writes the sanitizer and sink rules of the positions of every callee of f,
+// callees are resolved as the switcher resolves them
+func writeCallees(w io.Writer, task *TaintConfig, f *ssa.Function) {
+	kindRuler, _ := task.Ruler.(rule.KindRuler)
+	for _, b := range f.Blocks {
+		for _, inst := range b.Instrs {
+			call, ok := inst.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+			for _, callee := range calleesOf(task, call) {
+				for i := range call.Common().Args {
+					node := &Node{Function: callee, Canonical: callee.String(), Index: i, IsStatic: true}
+					fmt.Fprintln(w, "callee", callee.String(), i, task.Ruler.IsSanitizer(node), task.Ruler.IsSink(node))
+					if kindRuler != nil {
+						fmt.Fprintln(w, kindRuler.SanitizerKinds(node), kindRuler.SinkKinds(node))
+					}
+				}
+			}
+		}
+	}
+}
+
+// calleesOf returns the functions a call may reach, by its static callee, the call graph or the interface hierarchy
+func calleesOf(task *TaintConfig, call ssa.CallInstruction) []*ssa.Function {
+	common := call.Common()
+	if f := common.StaticCallee(); f != nil {
+		return []*ssa.Function{f}
+	}
+	callees := make([]*ssa.Function, 0)
+	if task.UsePointerAnalysis && task.CallGraph != nil {
+		if node := task.CallGraph.Nodes[call.Parent()]; node != nil {
+			for _, edge := range node.Out {
+				if edge.Site == call && edge.Callee.Func != nil {
+					callees = append(callees, edge.Callee.Func)
+				}
+			}
+		}
+	}
+	if common.IsInvoke() {
+		if tiface, ok := common.Value.Type().Underlying().(*types.Interface); ok {
+			callees = append(callees, task.InterfaceHierarchy.LookupMethods(tiface, common.Method)...)
+		}
+	} else if signature, ok := common.Value.Type().Underlying().(*types.Signature); ok {
+		callees = append(callees, task.InterfaceHierarchy.LookupFuncs(signature)...)
+	}
+	return callees
+}
+
+// writeFunc writes the SSA of f and the positions of its calls, since taint edges record them
+func writeFunc(w io.Writer, f *ssa.Function) {
+	var buf bytes.Buffer
+	ssa.WriteFunction(&buf, f)
+	w.Write(buf.Bytes())
+	for _, b := range f.Blocks {
+		for _, inst := range b.Instrs {
+			if call, ok := inst.(ssa.CallInstruction); ok {
+				fmt.Fprintln(w, "call", f.Prog.Fset.Position(call.Pos()))
+			}
+		}
+	}
+}
+
+// path returns the file of a key
+func (s *summaryCache) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key+".json")
+}
+
+// load replays the entry of key into the overlay of task, and returns whether it was cached
+func (s *summaryCache) load(key string, task *TaintConfig) bool {
+	res, err := os.ReadFile(s.path(key))
+	if err != nil {
+		s.misses.Add(1)
+		return false
+	}
+	entry := new(cacheEntry)
+	if err := json.Unmarshal(res, entry); err != nil {
+		s.misses.Add(1)
+		return false
+	}
+	for _, p := range entry.PassThrough {
+		task.setPassThrough(p.Function, p.PassThrough)
+	}
+	for _, e := range entry.Edges {
+		var node *Node
+		if e.Node != nil {
+			node = &Node{Canonical: e.Node.Canonical, Index: e.Node.Index, Position: e.Node.Position,
+				IsSignature: e.Node.IsSignature, IsMethod: e.Node.IsMethod, IsStatic: e.Node.IsStatic}
+		}
+		task.local.edges = append(task.local.edges, pendingEdge{key: e.Key, key2: e.Key2, edge: e.Edge, node: node})
+	}
+	s.hits.Add(1)
+	if s.debug {
+		fmt.Println("load cached summaries:", key)
+	}
+	return true
+}
+
+// store saves the overlay of task as the entry of key
+// overlays of functions which did not converge or recorded global functions are not stored,
+// since they depend on more than the key, failures of writing are ignored
+func (s *summaryCache) store(key string, task *TaintConfig) {
+	o := task.local
+	if len(o.notConverged) != 0 || len(o.initMap) != 0 {
+		return
+	}
+	entry := new(cacheEntry)
+	entry.PassThrough = make([]cachedPassThrough, 0, len(o.passThroughOrder))
+	for _, name := range o.passThroughOrder {
+		entry.PassThrough = append(entry.PassThrough, cachedPassThrough{Function: name, PassThrough: o.passThrough[name]})
+	}
+	entry.Edges = make([]cachedEdge, 0, len(o.edges))
+	for _, e := range o.edges {
+		var node *cachedNode
+		if e.node != nil {
+			node = &cachedNode{Canonical: e.node.Canonical, Index: e.node.Index, Position: e.node.Position,
+				IsSignature: e.node.IsSignature, IsMethod: e.node.IsMethod, IsStatic: e.node.IsStatic}
+		}
+		entry.Edges = append(entry.Edges, cachedEdge{Key: e.key, Key2: e.key2, Edge: e.edge, Node: node})
+	}
+	res, err := json.Marshal(entry)
+	if err == nil {
+		err = writeFileAtomic(s.path(key), res)
+	}
+	if err != nil && s.debug {
+		fmt.Println("failed to cache summaries:", err)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to dst,
+// so concurrent readers never see a partial entry
+func writeFileAtomic(dst string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(dst), "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), dst)
+}
@@ -0,0 +1,58 @@
+package taint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheMatchesUncached(t *testing.T) {
+	want := runGolden(t, newGoldenRunner(t, "parallel"))
+	cache := t.TempDir()
+	for i, workers := range []int{1, 4} {
+		r := newGoldenRunner(t, "parallel")
+		r.Workers = workers
+		r.CacheDir = cache
+		if got := runGolden(t, r); got != want {
+			t.Errorf("run %d with cache differs from run without cache\ngot:\n%s\nwant:\n%s", i, got, want)
+		}
+		if i == 0 && r.CacheHits != 0 {
+			t.Errorf("run %d: %d cache hits in an empty cache", i, r.CacheHits)
+		}
+		if i == 1 && r.CacheMisses != 0 {
+			t.Errorf("run %d: %d cache misses after caching the same code", i, r.CacheMisses)
+		}
+	}
+}
+
+func TestCacheInvalidatedByRules(t *testing.T) {
+	rules, err := os.ReadFile(filepath.Join(goldenDir, "rules.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// wrap becomes a sanitizer, only the rules change between the runs
+	sanitized := filepath.Join(t.TempDir(), "rules.yaml")
+	rules = append(rules, "  - {function: example.com/golden/parallel.wrap, kinds: [sqli]}\n"...)
+	if err := os.WriteFile(sanitized, rules, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := t.TempDir()
+	r := newGoldenRunner(t, "parallel")
+	r.CacheDir = cache
+	before := runGolden(t, r)
+
+	r = newGoldenRunner(t, "parallel")
+	r.RuleFiles = []string{sanitized}
+	want := runGolden(t, r)
+	if want == before {
+		t.Fatal("the sanitizer does not change the result, the test checks nothing")
+	}
+
+	r = newGoldenRunner(t, "parallel")
+	r.RuleFiles = []string{sanitized}
+	r.CacheDir = cache
+	if got := runGolden(t, r); got != want {
+		t.Errorf("cached run with new rules differs from uncached run\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
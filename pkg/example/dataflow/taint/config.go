@@ -2,7 +2,12 @@ package taint
 
 import (
 	"container/list"
+	"context"
 
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/andersen"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/worklist"
 	"github.com/zeroy0410/goot/pkg/example/dataflow/taint/rule"
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/ssa"
@@ -14,15 +19,26 @@ type TaintConfig struct {
 	InitMap              *map[string]*ssa.Function
 	History              *map[string]bool
 	CallStack            *list.List
-	InterfaceHierarchy   *InterfaceHierarchy
+	InterfaceHierarchy   *icfg.InterfaceHierarchy
 	TaintGraph           *TaintGraph
 	UsePointerAnalysis   bool
 	CallGraph            *callgraph.Graph
+	CallGraphAlgorithm   icfg.Algorithm // 构建 CallGraph 的算法
 	Ruler                rule.Ruler
 	PassThroughOnly      bool
 	TargetFunc           string
 	Debug                bool
 	PassBack             bool
+	Worklist             worklist.Kind
+	Exceptional          bool
+	FieldDepth           int              // 字段访问路径的最大长度，为 0 时字段的污点是整个值的污点
+	Aliases              *andersen.Result // 指针分析的结果，不为 nil 时 Store 和 load 的污点经过指向同一内存的别名传递
+	Context              context.Context  // 整个分析的上下文，结束后不再分析新的函数
+	MaxComputations      int              // 每个函数的最大计算次数，为 0 时使用 DefaultMaxComputations
+	Budget               solver.Budget    // 每个函数的时间和内存预算
+	MaxMemory            uint64           // 整个分析的堆内存上限，超过后不再分析新的函数
+	NotConverged         *[]NotConverged  // 没有到达不动点的函数
+	local                *overlay         // 并行分析时任务的写入，为 nil 时直接写共享的存储
 }
 
 // Gostd reprents all go standard library's PkgPath
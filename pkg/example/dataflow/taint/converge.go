@@ -0,0 +1,41 @@
+package taint
+
+import (
+	"time"
+
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"golang.org/x/tools/go/ssa"
+)
+
+// DefaultMaxComputations is the computation limit of a function when TaintConfig.MaxComputations is not set
+const DefaultMaxComputations = 3000
+
+// NotConverged represents a function whose analysis stopped before a fixpoint
+// its passthrough may miss taint, so results depending on it are unsound
+type NotConverged struct {
+	Function     string
+	Reason       solver.StopReason
+	Computations int           // computations done before the solver stopped, 0 if the function was skipped
+	Elapsed      time.Duration // time spent on the function
+}
+
+// recordNotConverged appends a NotConverged record of f to the config
+func recordNotConverged(f *ssa.Function, c *TaintConfig, reason solver.StopReason, computations int, elapsed time.Duration) {
+	if c.NotConverged == nil {
+		return
+	}
+	*c.NotConverged = append(*c.NotConverged, NotConverged{Function: f.String(), Reason: reason, Computations: computations, Elapsed: elapsed})
+}
+
+// exhausted returns why the whole run can not go on, or solver.Fixpoint if it can
+func (c *TaintConfig) exhausted() solver.StopReason {
+	if c.Context != nil {
+		if err := c.Context.Err(); err != nil {
+			return solver.ReasonOf(err)
+		}
+	}
+	if c.MaxMemory != 0 && solver.HeapAlloc() > c.MaxMemory {
+		return solver.MemoryLimit
+	}
+	return solver.Fixpoint
+}
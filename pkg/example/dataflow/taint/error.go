@@ -1,9 +1,6 @@
 package taint
 
-// NoMainPkgError represents a no main package error
-type NoMainPkgError struct {
-}
+import "github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
 
-func (e *NoMainPkgError) Error() string {
-	return "No main functions found in runner.PkgPath"
-}
+// NoMainPkgError represents a no main package error, it is the icfg.NoMainError of RTA
+type NoMainPkgError = icfg.NoMainError
@@ -0,0 +1,291 @@
+package taint
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// fieldSep separates the fields of an access path, e.g. t0.Body.x is field x of field Body of t0
+// taints of fields of parameters are access paths too, e.g. req.Query
+const fieldSep = "."
+
+// joinPath returns the access path of path under base, an empty path is base itself
+func joinPath(base string, path string) string {
+	if path == "" {
+		return base
+	}
+	if base == "" {
+		return path
+	}
+	return base + fieldSep + path
+}
+
+// splitPath returns the base of an access path and its fields, e.g. req and Query.x of req.Query.x
+func splitPath(name string) (string, string) {
+	base, path, _ := strings.Cut(name, fieldSep)
+	return base, path
+}
+
+// limitPath returns the first k fields of path, a longer path stands for the field it is cut to
+func limitPath(path string, k int) string {
+	if path == "" {
+		return path
+	}
+	fields := strings.Split(path, fieldSep)
+	if len(fields) <= k {
+		return path
+	}
+	return strings.Join(fields[:k], fieldSep)
+}
+
+// fieldTaint returns the taint of the field path of a value whose taint is taint, at most k fields,
+// e.g. req.Query of req
+func fieldTaint(taint string, path string, k int) string {
+	name, kinds := splitTaint(taint)
+	base, old := splitPath(name)
+	return joinTaint(joinPath(base, limitPath(joinPath(old, path), k)), kinds)
+}
+
+// fieldName returns the name of the i'th field of a struct or of a pointer to a struct
+func fieldName(typ types.Type, i int) (string, bool) {
+	if p, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+	if st, ok := typ.Underlying().(*types.Struct); ok && i < st.NumFields() {
+		return st.Field(i).Name(), true
+	}
+	return "", false
+}
+
+// fieldTaints returns the wrappers of the fields of name in flow, keyed by their access paths under name
+// marks of tuples like t0.1 are not fields
+func fieldTaints(flow *map[any]any, name string) map[string]*TaintWrapper {
+	prefix := name + fieldSep
+	fields := make(map[string]*TaintWrapper)
+	for key, wrapper := range *flow {
+		path, ok := strings.CutPrefix(key.(string), prefix)
+		if !ok || path == "" || (path[0] >= '0' && path[0] <= '9') {
+			continue
+		}
+		fields[path] = wrapper.(*TaintWrapper)
+	}
+	return fields
+}
+
+// fieldDepth returns the k-limit of access paths, 0 means taint is not field sensitive
+func (s *TaintSwitcher) fieldDepth() int {
+	return s.taintAnalysis.config.FieldDepth
+}
+
+// field returns the name of the i'th field of typ if taint is field sensitive
+func (s *TaintSwitcher) field(typ types.Type, i int) (string, bool) {
+	if s.fieldDepth() == 0 {
+		return "", false
+	}
+	return fieldName(typ, i)
+}
+
+// passTaint passes taint like PassTaint, and the taint of the fields of src to the same fields of dst
+func (s *TaintSwitcher) passTaint(dst string, src ...string) {
+	if s.fieldDepth() == 0 {
+		PassTaint(s.outMap, dst, src...)
+		return
+	}
+	taints := make(map[string]*TaintWrapper)
+	for _, name := range src {
+		s.copyPath(taints, dst, "", name, "", nil)
+	}
+	s.addTaints(taints)
+	GetTaintWrapper(s.outMap, dst)
+}
+
+// readField passes the taint of field of x to dst, and the taint of the fields under it to the fields of dst
+func (s *TaintSwitcher) readField(dst string, x string, field string) {
+	taints := make(map[string]*TaintWrapper)
+	s.copyPath(taints, dst, "", x, field, nil)
+	s.addTaints(taints)
+}
+
+// writeField passes the taint of src to field of x, and the taint of the fields of src to the fields under it
+func (s *TaintSwitcher) writeField(x string, field string, src string) {
+	taints := make(map[string]*TaintWrapper)
+	s.copyPath(taints, x, field, src, "", nil)
+	s.addTaints(taints)
+}
+
+// wholeTaint returns the taint of name and of all its fields, which is the taint of name passed as a whole
+func (s *TaintSwitcher) wholeTaint(name string) *TaintWrapper {
+	wrapper := GetTaintWrapper(s.outMap, name)
+	if s.fieldDepth() == 0 {
+		return wrapper
+	}
+	whole := NewTaintWrapper()
+	for taint := range *wrapper.innerTaint {
+		whole.AddTaint(taint)
+	}
+	for _, field := range fieldTaints(s.outMap, name) {
+		for taint := range *field.innerTaint {
+			whole.AddTaint(taint)
+		}
+	}
+	return whole
+}
+
+// copyPath adds the taint of the field path from of src to the field path to of dst into taints, sanitized for kinds
+// the taint of src and of the fields on the way to from is taint of the rest of from, e.g. req.Query of req,
+// and the fields under from are copied under to, paths longer than the k-limit are cut
+func (s *TaintSwitcher) copyPath(taints map[string]*TaintWrapper, dst string, to string, src string, from string, kinds []string) {
+	k := s.fieldDepth()
+	add := func(key string, wrapper *TaintWrapper) {
+		if len(kinds) != 0 {
+			wrapper = wrapper.Sanitize(kinds)
+		}
+		old, ok := taints[key]
+		if !ok {
+			old = NewTaintWrapper()
+			taints[key] = old
+		}
+		for taint := range *wrapper.innerTaint {
+			old.AddTaint(taint)
+		}
+	}
+	if k == 0 {
+		add(dst, GetTaintWrapper(s.outMap, src))
+		return
+	}
+	to = limitPath(to, k)
+	from = limitPath(from, k)
+	whole := NewTaintWrapper()
+	key, rest := src, from
+	for {
+		if wrapper, ok := (*s.outMap)[key]; ok {
+			for taint := range *wrapper.(*TaintWrapper).innerTaint {
+				whole.AddTaint(fieldTaint(taint, rest, k))
+			}
+		}
+		if rest == "" {
+			break
+		}
+		field, next, _ := strings.Cut(rest, fieldSep)
+		key, rest = key+fieldSep+field, next
+	}
+	add(joinPath(dst, to), whole)
+	for path, wrapper := range fieldTaints(s.outMap, key) {
+		add(joinPath(dst, limitPath(joinPath(to, path), k)), wrapper)
+	}
+}
+
+// addTaints adds taints keyed by access paths to the flow
+func (s *TaintSwitcher) addTaints(taints map[string]*TaintWrapper) {
+	for key, wrapper := range taints {
+		dst := GetTaintWrapper(s.outMap, key)
+		for taint := range *wrapper.innerTaint {
+			dst.AddTaint(taint)
+		}
+	}
+}
+
+// flowTaint returns the taint a call passes from the args of the parameters in froms to the index'th position to
+// of its callee, which is dst in the caller, and the taint of the fields of dst keyed by their access paths
+// a flow of the passthrough with field flows passes only the fields they read to the fields they write
+func (s *TaintSwitcher) flowTaint(cache *PassThroughCache, to string, index int, froms []int, args []string, dst string) (*TaintWrapper, map[string]*TaintWrapper) {
+	taints := make(map[string]*TaintWrapper)
+	for _, p := range froms {
+		flows := cache.FieldFlows(to, index, p)
+		if len(flows) == 0 || s.fieldDepth() == 0 {
+			s.copyPath(taints, dst, "", args[p], "", cache.SanitizedKinds(to, index, p))
+			continue
+		}
+		for _, flow := range flows {
+			s.copyPath(taints, dst, flow.Field, args[p], flow.FromField, flow.Kinds)
+		}
+	}
+	whole, ok := taints[dst]
+	if !ok {
+		whole = NewTaintWrapper()
+	}
+	delete(taints, dst)
+	return whole, taints
+}
+
+// fieldPosition represents a field of the receiver, a result or a param of a passthrough
+type fieldPosition struct {
+	to    string // "recv", "result" or "param"
+	index int
+	field string // access path of the field
+}
+
+// addFieldTaint merges the taint of a field of the index'th position to into the passthrough
+func (p *PassThrough) addFieldTaint(to string, index int, field string, wrapper *TaintWrapper) {
+	if p.fields == nil {
+		p.fields = make(map[fieldPosition]*TaintWrapper)
+	}
+	position := fieldPosition{to: to, index: index, field: field}
+	old, ok := p.fields[position]
+	if !ok {
+		old = NewTaintWrapper()
+		p.fields[position] = old
+	}
+	for taint := range *wrapper.innerTaint {
+		old.AddTaint(taint)
+	}
+}
+
+// addFlows records in c the flows from the parameters to the index'th position to, whose own taint is whole,
+// and returns the parameters flowing to it
+// the flows of a parameter reading or writing fields are recorded as field flows
+func (p *PassThrough) addFlows(c *PassThroughCache, to string, index int, whole *TaintWrapper) []int {
+	wrappers := map[string]*TaintWrapper{"": whole}
+	for position, wrapper := range p.fields {
+		if position.to == to && position.index == index {
+			wrappers[position.field] = wrapper
+		}
+	}
+	fields := make([]string, 0, len(wrappers))
+	for field := range wrappers {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	froms := make([]int, 0)
+	for j, name := range p.Names {
+		flows := make([]FieldFlow, 0)
+		var kinds []string
+		for _, field := range fields {
+			pathKinds := wrappers[field].PathKinds(name)
+			paths := make([]string, 0, len(pathKinds))
+			for path := range pathKinds {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			for _, path := range paths {
+				if len(flows) == 0 {
+					kinds = pathKinds[path]
+				} else {
+					kinds = intersectKinds(kinds, pathKinds[path])
+				}
+				flows = append(flows, FieldFlow{To: to, Index: index, Field: field, From: j, FromField: path, Kinds: pathKinds[path]})
+			}
+		}
+		if len(flows) == 0 {
+			continue
+		}
+		froms = append(froms, j)
+		c.addSanitized(to, index, j, kinds)
+		if len(flows) > 1 || flows[0].Field != "" || flows[0].FromField != "" {
+			c.Fields = append(c.Fields, flows...)
+		}
+	}
+	return froms
+}
+
+// returnFieldTaint merges the taint of the fields of name, the index'th position to, into the passthrough
+func (s *TaintSwitcher) returnFieldTaint(to string, index int, name string) {
+	if s.fieldDepth() == 0 {
+		return
+	}
+	for field, wrapper := range fieldTaints(s.outMap, name) {
+		s.taintAnalysis.passThrough.addFieldTaint(to, index, field, wrapper)
+	}
+}
@@ -0,0 +1,39 @@
+package taint
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFieldDepth(t *testing.T) {
+	for _, tc := range []struct {
+		depth int
+		want  []string
+	}{
+		// a tainted field taints the whole value
+		{0, []string{
+			"fields.Callee -> lib.Exec @ fields/fields.go:35:10",
+			"fields.Callee -> lib.Query @ fields/fields.go:34:11",
+			"fields.Deep -> lib.Query @ fields/fields.go:42:11",
+			"fields.Local -> lib.Exec @ fields/fields.go:27:10",
+			"fields.Local -> lib.Query @ fields/fields.go:26:11",
+		}},
+		// in.deep.x is cut to in.deep, which holds y
+		{2, []string{
+			"fields.Callee -> lib.Exec @ fields/fields.go:35:10",
+			"fields.Deep -> lib.Query @ fields/fields.go:42:11",
+			"fields.Local -> lib.Exec @ fields/fields.go:27:10",
+		}},
+		{3, []string{
+			"fields.Callee -> lib.Exec @ fields/fields.go:35:10",
+			"fields.Local -> lib.Exec @ fields/fields.go:27:10",
+		}},
+	} {
+		r := newGoldenRunner(t, "fields")
+		r.FieldDepth = tc.depth
+		runGolden(t, r)
+		if got := findingsOf(r); !slices.Equal(got, tc.want) {
+			t.Errorf("depth %d: findings %q, want %q", tc.depth, got, tc.want)
+		}
+	}
+}
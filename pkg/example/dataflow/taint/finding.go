@@ -0,0 +1,135 @@
+package taint
+
+import (
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zeroy0410/goot/pkg/example/dataflow/taint/rule"
+)
+
+// Finding represents a path of taint from a source node to a sink node
+type Finding struct {
+	Source      string   // canonical name of the source
+	SourceIndex int      // position of the source
+	Sink        string   // canonical name of the sink
+	SinkIndex   int      // position of the sink
+	Kinds       []string `json:",omitempty"` // kinds of taint the sink reports, empty means all
+	Path        []*Edge  // edges from the source to the sink, each carries the call moving the taint
+}
+
+// Findings walks the graph from every source node and returns a shortest path through every edge into a sink node
+// it reaches, so a sink called at different call sites gives a finding for each of them
+// a path is dropped when its edges are sanitized for every kind of the sink, kinds come from a rule.KindRuler
+// findings are sorted by source and sink
+func (g *TaintGraph) Findings(ruler rule.Ruler) []*Finding {
+	// 边的目标节点只记录在节点的 In 中
+	targets := make(map[*Edge]*Node)
+	keys := make([]string, 0, len(*g.Nodes))
+	for key, node := range *g.Nodes {
+		keys = append(keys, key)
+		for _, edge := range node.In {
+			targets[edge] = node
+		}
+	}
+	sort.Strings(keys)
+
+	findings := make([]*Finding, 0)
+	for _, key := range keys {
+		source := (*g.Nodes)[key]
+		if source.IsSource {
+			findings = append(findings, findPaths(source, targets, ruler)...)
+		}
+	}
+	return findings
+}
+
+// witness represents a state of the search, a node reached with the kinds its taint is sanitized for
+type witness struct {
+	node  *Node
+	kinds []string
+	edge  *Edge    // edge reaching the node
+	prev  *witness // state the edge comes from
+}
+
+// findPaths searches breadth first from a source, the states are nodes with sanitized kinds,
+// so a shorter sanitized path does not hide a longer one which is not
+// edges are keyed by call site, so the first witness of every edge into a sink is a finding
+func findPaths(source *Node, targets map[*Edge]*Node, ruler rule.Ruler) []*Finding {
+	kindRuler, _ := ruler.(rule.KindRuler)
+	findings := make([]*Finding, 0)
+	found := make(map[*Edge]bool)
+	visited := map[string]bool{stateKey(source, nil): true}
+	queue := []*witness{{node: source}}
+	for len(queue) != 0 {
+		w := queue[0]
+		queue = queue[1:]
+		for _, edge := range w.node.Out {
+			node, ok := targets[edge]
+			if !ok {
+				continue
+			}
+			kinds := w.kinds
+			if len(edge.Sanitized) != 0 {
+				kinds = unionKinds(kinds, edge.Sanitized)
+			}
+			next := &witness{node: node, kinds: kinds, edge: edge, prev: w}
+			if node.IsSink && !found[edge] {
+				var sinkKinds []string
+				if kindRuler != nil {
+					sinkKinds = kindRuler.SinkKinds(node)
+				}
+				if len(sinkKinds) == 0 || len(intersectKinds(sinkKinds, kinds)) != len(sinkKinds) {
+					found[edge] = true
+					findings = append(findings, newFinding(source, node, sinkKinds, next))
+				}
+			}
+			key := stateKey(node, kinds)
+			if !visited[key] {
+				visited[key] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Sink != findings[j].Sink {
+			return findings[i].Sink < findings[j].Sink
+		}
+		if findings[i].SinkIndex != findings[j].SinkIndex {
+			return findings[i].SinkIndex < findings[j].SinkIndex
+		}
+		// 同一个下沉节点的路径按最后一个调用点排序
+		return positionLess(findings[i].Path[len(findings[i].Path)-1].Position, findings[j].Path[len(findings[j].Path)-1].Position)
+	})
+	return findings
+}
+
+// positionLess returns whether a is before b, by file, line and column
+func positionLess(a token.Position, b token.Position) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
+
+// stateKey returns the key of a node with sanitized kinds
+func stateKey(node *Node, kinds []string) string {
+	return node.Canonical + "#" + strconv.Itoa(node.Index) + "|" + strings.Join(kinds, ",")
+}
+
+// newFinding returns the finding of the path ending at w
+func newFinding(source *Node, sink *Node, kinds []string, w *witness) *Finding {
+	path := make([]*Edge, 0)
+	for ; w.prev != nil; w = w.prev {
+		path = append(path, w.edge)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return &Finding{Source: source.Canonical, SourceIndex: source.Index,
+		Sink: sink.Canonical, SinkIndex: path[len(path)-1].ToIndex, Kinds: kinds, Path: path}
+}
@@ -0,0 +1,26 @@
+package taint
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindingsPerCallSite(t *testing.T) {
+	r := newGoldenRunner(t, "findings")
+	runGolden(t, r)
+	// two calls of Query and one of Exec through run
+	want := []string{
+		"findings.Handler -> lib.Exec @ findings/findings.go:6:30",
+		"findings.Handler -> lib.Query @ findings/findings.go:10:11",
+		"findings.Handler -> lib.Query @ findings/findings.go:12:12",
+	}
+	if got := findingsOf(r); !slices.Equal(got, want) {
+		t.Errorf("findings %q, want %q", got, want)
+	}
+	for _, finding := range r.Findings {
+		last := finding.Path[len(finding.Path)-1]
+		if finding.Path[0].From != finding.Source || last.To != finding.Sink {
+			t.Errorf("path of %s to %s is not complete: %v", finding.Source, finding.Sink, finding.Path)
+		}
+	}
+}
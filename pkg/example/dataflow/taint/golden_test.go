@@ -0,0 +1,70 @@
+package taint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// goldenDir is the module of the packages the runner tests analyze
+const goldenDir = "testdata/golden"
+
+// newGoldenRunner returns a Runner of a package of goldenDir with the rules of goldenDir,
+// functions of the package are intra and the lib package is not
+func newGoldenRunner(t *testing.T, pkg string) *Runner {
+	t.Helper()
+	dir, err := filepath.Abs(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner("./" + pkg)
+	r.Dir = dir
+	r.ModuleName = "example.com/golden/" + pkg
+	r.RuleFiles = []string{filepath.Join(dir, "rules.yaml")}
+	return r
+}
+
+// runGolden runs r and returns its findings, taint edges and passthroughs as text,
+// files are relative to goldenDir so the text does not depend on the checkout
+func runGolden(t *testing.T, r *Runner) string {
+	t.Helper()
+	tmp := t.TempDir()
+	r.PassThroughDstPath = filepath.Join(tmp, "passthrough.json")
+	r.TaintGraphDstPath = filepath.Join(tmp, "taintgraph.json")
+	if err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := json.MarshalIndent(r.Findings, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	passThrough, err := os.ReadFile(r.PassThroughDstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taintGraph, err := os.ReadFile(r.TaintGraphDstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(findings) + "\n" + string(taintGraph) + "\n" + string(passThrough) + "\n"
+	return strings.ReplaceAll(out, r.Dir+string(filepath.Separator), "")
+}
+
+// findingsOf returns every finding of r as "source -> sink @ file:line:col" of its sink call,
+// files are relative to the directory of r and packages of goldenDir lose their module prefix
+func findingsOf(r *Runner) []string {
+	lines := make([]string, 0, len(r.Findings))
+	for _, finding := range r.Findings {
+		position := finding.Path[len(finding.Path)-1].Position
+		file, err := filepath.Rel(r.Dir, position.Filename)
+		if err != nil {
+			file = position.Filename
+		}
+		line := fmt.Sprintf("%s -> %s @ %s:%d:%d", finding.Source, finding.Sink, filepath.ToSlash(file), position.Line, position.Column)
+		lines = append(lines, strings.ReplaceAll(line, "example.com/golden/", ""))
+	}
+	return lines
+}
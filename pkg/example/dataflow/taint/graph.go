@@ -1,7 +1,9 @@
 package taint
 
 import (
+	"go/token"
 	"strconv"
+	"sync"
 
 	"github.com/zeroy0410/goot/pkg/example/dataflow/taint/rule"
 	"golang.org/x/tools/go/ssa"
@@ -11,6 +13,43 @@ import (
 type TaintGraph struct {
 	Nodes *map[string]*Node
 	Edges *map[string]*Edge
+	mu    sync.Mutex // guards Nodes and Edges in AddEdge
+}
+
+// AddEdge adds edge from the node of key to the node of key2 and returns whether it is new
+// edges of different call sites are kept apart, the key of an edge is EdgeKey
+// if there is no node of key2, newNode creates it, a nil newNode leaves the edge without a target node
+// an edge which is already added keeps the kinds sanitized for both of them
+func (g *TaintGraph) AddEdge(key string, key2 string, edge *Edge, newNode func() *Node) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	edgeKey := EdgeKey(key, key2, edge)
+	if old, ok := (*g.Edges)[edgeKey]; ok {
+		if len(old.Sanitized) != 0 {
+			old.Sanitized = intersectKinds(old.Sanitized, edge.Sanitized)
+		}
+		return false
+	}
+	(*g.Edges)[edgeKey] = edge
+	if node, ok := (*g.Nodes)[key]; ok {
+		node.Out = append(node.Out, edge)
+	}
+	node2, ok := (*g.Nodes)[key2]
+	if !ok {
+		if newNode == nil {
+			return true
+		}
+		node2 = newNode()
+		(*g.Nodes)[key2] = node2
+	}
+	node2.In = append(node2.In, edge)
+	passProperty(node2, edge)
+	return true
+}
+
+// EdgeKey returns the key of an edge from the node of key to the node of key2 at its call site
+func EdgeKey(key string, key2 string, edge *Edge) string {
+	return key + "#" + key2 + "@" + edge.Position.String()
 }
 
 // NewTaintGraph returns a TaintGraph
@@ -22,13 +61,13 @@ func NewTaintGraph(allFuncs *map[*ssa.Function]bool, ruler rule.Ruler) *TaintGra
 	callGraph.Edges = &edges
 	for f := range *allFuncs {
 		if f.Signature.Recv() != nil {
-			node := &Node{Function: f, Canonical: f.String(), Index: 0, Out: make([]*Edge, 0), In: make([]*Edge, 0)}
+			node := &Node{Function: f, Canonical: f.String(), Index: 0, Position: paramPosition(f, 0), Out: make([]*Edge, 0), In: make([]*Edge, 0)}
 			decidePropertry(node, ruler)
 			node.IsStatic = true
 			(*callGraph.Nodes)[f.String()+"#"+strconv.Itoa(0)] = node
 			n := f.Signature.Params().Len()
 			for i := 0; i < n; i++ {
-				node := &Node{Function: f, Canonical: f.String(), Index: i + 1, Out: make([]*Edge, 0), In: make([]*Edge, 0)}
+				node := &Node{Function: f, Canonical: f.String(), Index: i + 1, Position: paramPosition(f, i+1), Out: make([]*Edge, 0), In: make([]*Edge, 0)}
 				decidePropertry(node, ruler)
 				node.IsStatic = true
 				(*callGraph.Nodes)[f.String()+"#"+strconv.Itoa(i+1)] = node
@@ -36,7 +75,7 @@ func NewTaintGraph(allFuncs *map[*ssa.Function]bool, ruler rule.Ruler) *TaintGra
 		} else {
 			n := f.Signature.Params().Len()
 			for i := 0; i < n; i++ {
-				node := &Node{Function: f, Canonical: f.String(), Index: i, Out: make([]*Edge, 0), In: make([]*Edge, 0)}
+				node := &Node{Function: f, Canonical: f.String(), Index: i, Position: paramPosition(f, i), Out: make([]*Edge, 0), In: make([]*Edge, 0)}
 				decidePropertry(node, ruler)
 				node.IsStatic = true
 				(*callGraph.Nodes)[f.String()+"#"+strconv.Itoa(i)] = node
@@ -57,10 +96,38 @@ type Node struct {
 	IsIntra     bool
 	Canonical   string
 	Index       int
+	Position    token.Position // declaration of the parameter, or of the function if the parameter has none
 	Out         []*Edge
 	In          []*Edge
 }
 
+// paramPosition returns the position of the i'th parameter of f, the receiver is 0
+func paramPosition(f *ssa.Function, i int) token.Position {
+	pos := f.Pos()
+	if i < len(f.Params) && f.Params[i].Pos().IsValid() {
+		pos = f.Params[i].Pos()
+	}
+	return f.Prog.Fset.Position(pos)
+}
+
+// TargetName returns the canonical name of the node, it makes Node a rule.Target
+func (node *Node) TargetName() string {
+	return node.Canonical
+}
+
+// TargetIndex returns the position of the node, a node of a method or a signature stands for every position
+func (node *Node) TargetIndex() int {
+	if node.IsMethod || node.IsSignature {
+		return -1
+	}
+	return node.Index
+}
+
+// TargetFunc returns the function of the node
+func (node *Node) TargetFunc() *ssa.Function {
+	return node.Function
+}
+
 // Edge represents a taint edge
 type Edge struct {
 	From          string
@@ -71,4 +138,8 @@ type Edge struct {
 	ToIsSink      bool
 	ToIsSignature bool
 	ToIsStatic    bool
+	Sanitized     []string       `json:",omitempty"` // kinds the taint is sanitized for on every path of the edge
+	Position      token.Position // position of the call which moves the taint
+	Instruction   string         // SSA of the call which moves the taint
+	Call          int            `json:",omitempty"` // ordinal of the call among the calls of the same callee in the caller
 }
@@ -0,0 +1,33 @@
+package taint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNodePositions(t *testing.T) {
+	r := newGoldenRunner(t, "parallel")
+	runGolden(t, r)
+	// every node is at a declaration, run of the runner interface and the parameter fn of Apply included
+	want := map[string]string{
+		"func (example.com/golden/parallel.runner).run(s string)#0": "parallel/parallel.go:7:2",
+		"func(string)#0":                        "parallel/parallel.go:51:12",
+		"example.com/golden/lib.Query#0":        "lib/lib.go:17:12",
+		"example.com/golden/parallel.Handler#0": "parallel/parallel.go:38:14",
+	}
+	for key, node := range *r.Graph.Nodes {
+		if !node.Position.IsValid() {
+			t.Errorf("node %s has no position", key)
+			continue
+		}
+		if w, ok := want[key]; ok {
+			if got := strings.TrimPrefix(node.Position.String(), r.Dir+"/"); got != w {
+				t.Errorf("node %s is at %s, want %s", key, got, w)
+			}
+			delete(want, key)
+		}
+	}
+	for key := range want {
+		t.Errorf("no node %s", key)
+	}
+}
@@ -0,0 +1,187 @@
+package taint
+
+import (
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/ssa"
+)
+
+// sortedFuncs returns the functions sorted by name
+func sortedFuncs(funcs map[*ssa.Function]bool) []*ssa.Function {
+	sorted := make([]*ssa.Function, 0, len(funcs))
+	for f := range funcs {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+	return sorted
+}
+
+// component represents a strongly connected component of the call graph
+type component struct {
+	Funcs []*ssa.Function // functions of the component, sorted by name
+	Deps  []*ssa.Function // functions out of the component it calls or refers to, sorted by name
+}
+
+// schedule splits funcs into waves of strongly connected components of cg, callees come first
+// components of a wave do not depend on each other, so they can be analyzed at the same time
+// both the waves and the components in a wave are sorted by name
+func schedule(funcs []*ssa.Function, cg *callgraph.Graph) [][]*component {
+	in := make(map[*ssa.Function]bool, len(funcs))
+	for _, f := range funcs {
+		in[f] = true
+	}
+	// 被调用的函数和引用的函数都是依赖，按名字排序使调度结果确定
+	deps := func(f *ssa.Function) []*ssa.Function {
+		set := make(map[*ssa.Function]bool)
+		if node := cg.Nodes[f]; node != nil {
+			for _, edge := range node.Out {
+				set[edge.Callee.Func] = true
+			}
+		}
+		for _, b := range f.Blocks {
+			for _, inst := range b.Instrs {
+				for _, op := range inst.Operands(nil) {
+					if g, ok := (*op).(*ssa.Function); ok {
+						set[g] = true
+					}
+				}
+			}
+		}
+		delete(set, nil)
+		result := make([]*ssa.Function, 0, len(set))
+		for g := range set {
+			if in[g] {
+				result = append(result, g)
+			}
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].String() < result[j].String()
+		})
+		return result
+	}
+
+	// Tarjan 算法按逆拓扑序给出强连通分量，即被调用者先于调用者
+	index := make(map[*ssa.Function]int, len(funcs))
+	low := make(map[*ssa.Function]int, len(funcs))
+	onStack := make(map[*ssa.Function]bool)
+	componentOf := make(map[*ssa.Function]int, len(funcs))
+	stack := make([]*ssa.Function, 0)
+	sccs := make([]*component, 0)
+	levels := make([]int, 0)
+	var visit func(f *ssa.Function)
+	visit = func(f *ssa.Function) {
+		index[f] = len(index)
+		low[f] = index[f]
+		stack = append(stack, f)
+		onStack[f] = true
+		for _, g := range deps(f) {
+			if _, ok := index[g]; !ok {
+				visit(g)
+				low[f] = min(low[f], low[g])
+			} else if onStack[g] {
+				low[f] = min(low[f], index[g])
+			}
+		}
+		if low[f] != index[f] {
+			return
+		}
+		scc := make(map[*ssa.Function]bool)
+		for {
+			g := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[g] = false
+			componentOf[g] = len(sccs)
+			scc[g] = true
+			if g == f {
+				break
+			}
+		}
+		// 分量的层数比它依赖的分量都大，依赖的分量已经编号
+		level := 0
+		external := make(map[*ssa.Function]bool)
+		for g := range scc {
+			for _, h := range deps(g) {
+				if c := componentOf[h]; c != len(sccs) {
+					level = max(level, levels[c]+1)
+					external[h] = true
+				}
+			}
+		}
+		sccs = append(sccs, &component{Funcs: sortedFuncs(scc), Deps: sortedFuncs(external)})
+		levels = append(levels, level)
+	}
+	for _, f := range funcs {
+		if _, ok := index[f]; !ok {
+			visit(f)
+		}
+	}
+
+	waves := make([][]*component, 0)
+	for i, scc := range sccs {
+		for len(waves) <= levels[i] {
+			waves = append(waves, make([]*component, 0))
+		}
+		waves[levels[i]] = append(waves[levels[i]], scc)
+	}
+	for _, wave := range waves {
+		sort.Slice(wave, func(i, j int) bool {
+			return wave[i].Funcs[0].String() < wave[j].Funcs[0].String()
+		})
+	}
+	return waves
+}
+
+// runParallel analyzes the waves one by one, the components of a wave are analyzed by workers goroutines
+// every component is a task with its own overlay, and the overlays are committed in the order of the wave
+// after all tasks of the wave finish, so the results do not depend on how tasks are scheduled
+// with a cache, a component whose key is cached replays the cached overlay instead of being analyzed
+func runParallel(c *TaintConfig, waves [][]*component, workers int, cache *summaryCache) {
+	workers = max(workers, 1)
+	for _, wave := range waves {
+		tasks := make([]*TaintConfig, len(wave))
+		for i := range wave {
+			tasks[i] = c.fork()
+		}
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					runComponent(wave[i], tasks[i], cache)
+				}
+			}()
+		}
+		for i := range wave {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		for _, task := range tasks {
+			c.commit(task)
+		}
+	}
+}
+
+// runComponent analyzes the functions of a component in a task, or loads them from the cache
+func runComponent(scc *component, task *TaintConfig, cache *summaryCache) {
+	if cache == nil {
+		for _, f := range scc.Funcs {
+			Run(f, task)
+		}
+		return
+	}
+	key := cache.key(task, scc)
+	if cache.load(key, task) {
+		return
+	}
+	for _, f := range scc.Funcs {
+		Run(f, task)
+	}
+	cache.store(key, task)
+}
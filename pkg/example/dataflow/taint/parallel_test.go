@@ -0,0 +1,25 @@
+package taint
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParallelMatchesSequential(t *testing.T) {
+	r := newGoldenRunner(t, "parallel")
+	want := runGolden(t, r)
+	findings := []string{
+		"parallel.Handler -> lib.Query @ parallel/parallel.go:40:11",
+		"parallel.Handler -> lib.Render @ parallel/parallel.go:43:34",
+	}
+	if got := findingsOf(r); !slices.Equal(got, findings) {
+		t.Errorf("findings %q, want %q", got, findings)
+	}
+	for _, workers := range []int{2, 4, 8} {
+		r := newGoldenRunner(t, "parallel")
+		r.Workers = workers
+		if got := runGolden(t, r); got != want {
+			t.Errorf("workers=%d differs from workers=1\ngot:\n%s\nwant:\n%s", workers, got, want)
+		}
+	}
+}
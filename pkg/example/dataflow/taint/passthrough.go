@@ -6,6 +6,7 @@ type PassThrough struct {
 	Recv    *TaintWrapper
 	Results []*TaintWrapper
 	Params  []*TaintWrapper
+	fields  map[fieldPosition]*TaintWrapper // taints of fields of the receiver, results and params
 }
 
 // PassThroughCache represents a passthrough cache
@@ -13,6 +14,29 @@ type PassThroughCache struct {
 	Recv    []int
 	Results [][]int
 	Params  [][]int
+	// Sanitized records the flows above whose taint is sanitized for some kinds on every path
+	Sanitized []SanitizedFlow `json:",omitempty"`
+	// Fields records the flows above which read or write fields, a flow with field flows passes only them
+	Fields []FieldFlow `json:",omitempty"`
+}
+
+// SanitizedFlow represents a flow of a passthrough sanitized for some kinds
+type SanitizedFlow struct {
+	To    string   // "recv", "result" or "param"
+	Index int      // index of the result or the param, 0 for the receiver
+	From  int      // index of the parameter the taint comes from
+	Kinds []string // kinds of taint the flow is sanitized for
+}
+
+// FieldFlow represents a flow of a passthrough from a field of a parameter to a field of a position,
+// e.g. field Query of param 1 flows to result 0
+type FieldFlow struct {
+	To        string   // "recv", "result" or "param"
+	Index     int      // index of the result or the param, 0 for the receiver
+	Field     string   `json:",omitempty"` // access path written in the position, empty means the position itself
+	From      int      // index of the parameter the taint comes from
+	FromField string   `json:",omitempty"` // access path read from the parameter, empty means the parameter itself
+	Kinds     []string `json:",omitempty"` // kinds of taint the flow is sanitized for
 }
 
 // NewPassThrough return a PassThrough
@@ -42,38 +66,19 @@ func NewPassThrough(names []string, recv bool, result int, param int) *PassThrou
 // ToCache tranforms a passthrough to a passthrough cache
 func (p *PassThrough) ToCache() *PassThroughCache {
 	passThroughCache := NewPassThroughCache(false, 0, 0)
-	n := len(p.Names)
 	if p.HasRecv() {
-		singlePassThrough := make([]int, 0)
-		for i := 0; i < n; i++ {
-			// for reciver, checks its taints from which param, and records
-			if ok := p.Recv.HasTaint(p.Names[i]); ok {
-				singlePassThrough = append(singlePassThrough, i)
-			}
-		}
-		passThroughCache.Recv = singlePassThrough
+		// for reciver, checks its taints from which param, and records
+		passThroughCache.Recv = p.addFlows(passThroughCache, "recv", 0, p.Recv)
 	}
 	m := p.ResultNum()
 	for i := 0; i < m; i++ {
-		singlePassThrough := make([]int, 0)
-		for j := 0; j < n; j++ {
-			// for every return value, checks its taints from which param, and records
-			if ok := p.Results[i].HasTaint(p.Names[j]); ok {
-				singlePassThrough = append(singlePassThrough, j)
-			}
-		}
-		passThroughCache.Results = append(passThroughCache.Results, singlePassThrough)
+		// for every return value, checks its taints from which param, and records
+		passThroughCache.Results = append(passThroughCache.Results, p.addFlows(passThroughCache, "result", i, p.Results[i]))
 	}
 	m = p.ParamNum()
 	for i := 0; i < m; i++ {
-		singlePassThrough := make([]int, 0)
-		for j := 0; j < n; j++ {
-			// for every parameter value, checks its taints from which param, and records
-			if ok := p.Params[i].HasTaint(p.Names[j]); ok {
-				singlePassThrough = append(singlePassThrough, j)
-			}
-		}
-		passThroughCache.Params = append(passThroughCache.Params, singlePassThrough)
+		// for every parameter value, checks its taints from which param, and records
+		passThroughCache.Params = append(passThroughCache.Params, p.addFlows(passThroughCache, "param", i, p.Params[i]))
 	}
 	return passThroughCache
 }
@@ -140,3 +145,31 @@ func (c *PassThroughCache) ResultNum() int {
 func (c *PassThroughCache) ParamNum() int {
 	return len(c.Params)
 }
+
+// addSanitized records a flow sanitized for kinds, a flow without kinds is not recorded
+func (c *PassThroughCache) addSanitized(to string, index int, from int, kinds []string) {
+	if len(kinds) != 0 {
+		c.Sanitized = append(c.Sanitized, SanitizedFlow{To: to, Index: index, From: from, Kinds: kinds})
+	}
+}
+
+// SanitizedKinds returns the kinds the flow from the from'th parameter to a position is sanitized for
+func (c *PassThroughCache) SanitizedKinds(to string, index int, from int) []string {
+	for _, flow := range c.Sanitized {
+		if flow.To == to && flow.Index == index && flow.From == from {
+			return flow.Kinds
+		}
+	}
+	return nil
+}
+
+// FieldFlows returns the field flows from the from'th parameter to a position
+func (c *PassThroughCache) FieldFlows(to string, index int, from int) []FieldFlow {
+	flows := make([]FieldFlow, 0)
+	for _, flow := range c.Fields {
+		if flow.To == to && flow.Index == index && flow.From == from {
+			flows = append(flows, flow)
+		}
+	}
+	return flows
+}
@@ -42,6 +42,21 @@ func PersistTaintGraph(edges *map[string]*Edge, dst string) error {
 	return nil
 }
 
+// PersistFindings stores findings to target destination
+func PersistFindings(findings []*Finding, dst string) error {
+	f, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	res, err := json.Marshal(findings)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(f, string(res))
+	f.Close()
+	return nil
+}
+
 // PersistToNeo4j stores taint edges to neo4j database
 func PersistToNeo4j(nodes *map[string]*Node, edges *map[string]*Edge, uri string, username string, password string) {
 	driver, err := neo4j.NewDriver(uri, neo4j.BasicAuth(username, password, ""))
@@ -58,16 +73,19 @@ func PersistToNeo4j(nodes *map[string]*Node, edges *map[string]*Edge, uri string
 		_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (any, error) {
 			if node.IsSource && node.IsIntra && len(node.Out) != 0 {
 				_, _ = transaction.Run(
-					"CREATE (node:Source) SET node={id:$Id, name:$Canonical, index:$Index}",
-					map[string]any{"Id": id, "Canonical": node.Canonical, "Index": node.Index})
+					"CREATE (node:Source) SET node={id:$Id, name:$Canonical, index:$Index, file:$File, line:$Line, column:$Column}",
+					map[string]any{"Id": id, "Canonical": node.Canonical, "Index": node.Index,
+						"File": node.Position.Filename, "Line": node.Position.Line, "Column": node.Position.Column})
 			} else if node.IsSink && len(node.In) != 0 {
 				_, _ = transaction.Run(
-					"CREATE (node:Sink) SET node={id:$Id, name:$Canonical, index:$Index}",
-					map[string]any{"Id": id, "Canonical": node.Canonical, "Index": node.Index})
+					"CREATE (node:Sink) SET node={id:$Id, name:$Canonical, index:$Index, file:$File, line:$Line, column:$Column}",
+					map[string]any{"Id": id, "Canonical": node.Canonical, "Index": node.Index,
+						"File": node.Position.Filename, "Line": node.Position.Line, "Column": node.Position.Column})
 			} else if node.IsIntra && len(node.In)+len(node.Out) != 0 {
 				_, _ = transaction.Run(
-					"CREATE (node:Intra) SET node={id:$Id, name:$Canonical, index:$Index}",
-					map[string]any{"Id": id, "Canonical": node.Canonical, "Index": node.Index})
+					"CREATE (node:Intra) SET node={id:$Id, name:$Canonical, index:$Index, file:$File, line:$Line, column:$Column}",
+					map[string]any{"Id": id, "Canonical": node.Canonical, "Index": node.Index,
+						"File": node.Position.Filename, "Line": node.Position.Line, "Column": node.Position.Column})
 			}
 			return nil, nil
 		})
@@ -80,8 +98,10 @@ func PersistToNeo4j(nodes *map[string]*Node, edges *map[string]*Edge, uri string
 		id2 := strconv.FormatUint(maphash.String(seed, edge.To+strconv.Itoa(edge.ToIndex)), 10)
 		_, err = session.WriteTransaction(func(transaction neo4j.Transaction) (any, error) {
 			_, _ = transaction.Run(
-				"MATCH (from),(to) WHERE from.id=$Id1 and to.id=$Id2 CREATE (from)-[r:CALL]->(to)",
-				map[string]any{"Id1": id1, "Id2": id2})
+				"MATCH (from),(to) WHERE from.id=$Id1 and to.id=$Id2 "+
+					"CREATE (from)-[r:CALL {file:$File, line:$Line, column:$Column, instruction:$Instruction}]->(to)",
+				map[string]any{"Id1": id1, "Id2": id2, "File": edge.Position.Filename, "Line": edge.Position.Line,
+					"Column": edge.Position.Column, "Instruction": edge.Instruction})
 			return nil, nil
 		})
 		if err != nil {
@@ -5,4 +5,18 @@ type Ruler interface {
 	IsSink(any) bool
 	IsSource(any) bool
 	IsIntra(any) bool
+	IsSanitizer(any) bool
+}
+
+// KindRuler is implemented by a Ruler which knows kinds of taint, e.g. sqli or xss
+// a sanitizer only clears the kinds it covers, and a sink is not reached by taint sanitized for all its kinds
+type KindRuler interface {
+	SinkKinds(any) []string      // kinds of taint a sink reports, nil means all
+	SanitizerKinds(any) []string // kinds of taint a sanitizer clears, nil means all
+}
+
+// DigestRuler is implemented by a Ruler which can summarize its rules,
+// the digest changes whenever a node may be decided differently, e.g. it keys cached summaries
+type DigestRuler interface {
+	Digest() string
 }
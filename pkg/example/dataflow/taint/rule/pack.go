@@ -0,0 +1,392 @@
+package rule
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default.yaml
+var defaultPack []byte
+
+// Rule represents an entry of a rule pack, a node matches it if it meets every criterion set
+type Rule struct {
+	Function string   `json:"function,omitempty" yaml:"function,omitempty"` // canonical name, e.g. (*database/sql.DB).Exec
+	Package  string   `json:"package,omitempty" yaml:"package,omitempty"`   // package path of the function or of its receiver
+	Receiver string   `json:"receiver,omitempty" yaml:"receiver,omitempty"` // receiver type, e.g. *database/sql.DB
+	Name     string   `json:"name,omitempty" yaml:"name,omitempty"`         // name of the function or method
+	Regex    string   `json:"regex,omitempty" yaml:"regex,omitempty"`       // regular expression on the canonical name
+	Params   []string `json:"params,omitempty" yaml:"params,omitempty"`     // types the parameters of the function contain
+	Embeds   string   `json:"embeds,omitempty" yaml:"embeds,omitempty"`     // type of a field of the receiver struct
+	Args     []int    `json:"args,omitempty" yaml:"args,omitempty"`         // positions of the function, the receiver is 0, empty means all
+	Kinds    []string `json:"kinds,omitempty" yaml:"kinds,omitempty"`       // kinds of taint a sink reports or a sanitizer clears, empty means all
+	regex    *regexp.Regexp
+}
+
+// Pack represents rules of sources, sinks and sanitizers, it implements Ruler
+// nodes of the taint graph are positions of parameters, so a source rule without Args marks every node
+// of its function, like os.ReadFile in the DummyRuler, results of calls have no positions to mark
+type Pack struct {
+	Modules    []string `json:"modules,omitempty" yaml:"modules,omitempty"` // module names, functions of them are intra
+	Sources    []*Rule  `json:"sources,omitempty" yaml:"sources,omitempty"`
+	Sinks      []*Rule  `json:"sinks,omitempty" yaml:"sinks,omitempty"`
+	Sanitizers []*Rule  `json:"sanitizers,omitempty" yaml:"sanitizers,omitempty"`
+	sources    *ruleSet
+	sinks      *ruleSet
+	sanitizers *ruleSet
+}
+
+// ruleSet represents compiled rules, rules of an exact Function are indexed by it
+type ruleSet struct {
+	byFunction map[string][]*Rule
+	others     []*Rule
+}
+
+// LoadPack loads rule packs from files and merges them, a .json file is json and others are yaml
+func LoadPack(paths ...string) (*Pack, error) {
+	pack := new(Pack)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		format := "yaml"
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = "json"
+		}
+		p, err := ParsePack(data, format)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		pack.merge(p)
+	}
+	if err := pack.compile(); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// ParsePack parses a rule pack in format "json" or "yaml"
+func ParsePack(data []byte, format string) (*Pack, error) {
+	pack := new(Pack)
+	var err error
+	switch format {
+	case "json":
+		// unknown keys are errors, so a misspelled criterion does not widen a rule
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(pack)
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(pack); err == io.EOF {
+			// an empty pack has no rules
+			err = nil
+		}
+	default:
+		err = fmt.Errorf("unknown rule format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := pack.compile(); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// DefaultPack returns the built-in rule pack, which has the sinks and sources of the DummyRuler
+// modules are not set, so add them before using it
+func DefaultPack() *Pack {
+	pack, err := ParsePack(defaultPack, "yaml")
+	if err != nil {
+		panic(err)
+	}
+	return pack
+}
+
+// merge appends the rules of p to the pack
+func (pack *Pack) merge(p *Pack) {
+	pack.Modules = append(pack.Modules, p.Modules...)
+	pack.Sources = append(pack.Sources, p.Sources...)
+	pack.Sinks = append(pack.Sinks, p.Sinks...)
+	pack.Sanitizers = append(pack.Sanitizers, p.Sanitizers...)
+}
+
+// compile checks the rules and indexes them
+func (pack *Pack) compile() error {
+	var err error
+	if pack.sources, err = newRuleSet(pack.Sources, "source"); err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	if pack.sinks, err = newRuleSet(pack.Sinks, "sink"); err != nil {
+		return fmt.Errorf("sink: %w", err)
+	}
+	if pack.sanitizers, err = newRuleSet(pack.Sanitizers, "sanitizer"); err != nil {
+		return fmt.Errorf("sanitizer: %w", err)
+	}
+	return nil
+}
+
+// newRuleSet compiles rules of a role, only sinks and sanitizers have Kinds
+func newRuleSet(rules []*Rule, role string) (*ruleSet, error) {
+	s := new(ruleSet)
+	s.byFunction = make(map[string][]*Rule)
+	s.others = make([]*Rule, 0)
+	for _, r := range rules {
+		if r.Function == "" && r.Package == "" && r.Receiver == "" && r.Name == "" &&
+			r.Regex == "" && len(r.Params) == 0 && r.Embeds == "" {
+			return nil, errors.New("rule matches every function")
+		}
+		if len(r.Kinds) != 0 && role == "source" {
+			return nil, errors.New("kinds only apply to sinks and sanitizers")
+		}
+		if r.Regex != "" {
+			regex, err := regexp.Compile(r.Regex)
+			if err != nil {
+				return nil, err
+			}
+			r.regex = regex
+		}
+		if r.Function != "" {
+			s.byFunction[r.Function] = append(s.byFunction[r.Function], r)
+		} else {
+			s.others = append(s.others, r)
+		}
+	}
+	return s, nil
+}
+
+// match returns whether a rule of the set matches the node
+func (s *ruleSet) match(_f any) bool {
+	return len(s.matches(_f)) != 0
+}
+
+// matches returns the rules of the set matching the node
+func (s *ruleSet) matches(_f any) []*Rule {
+	t, ok := toTarget(_f)
+	if !ok {
+		return nil
+	}
+	rules := make([]*Rule, 0)
+	for _, r := range s.byFunction[t.name] {
+		if r.match(t) {
+			rules = append(rules, r)
+		}
+	}
+	for _, r := range s.others {
+		if r.match(t) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// kinds returns the kinds of the rules matching the node, nil if a rule has no kinds
+func (s *ruleSet) kinds(_f any) []string {
+	set := make(map[string]bool)
+	for _, r := range s.matches(_f) {
+		if len(r.Kinds) == 0 {
+			return nil
+		}
+		for _, kind := range r.Kinds {
+			set[kind] = true
+		}
+	}
+	kinds := make([]string, 0, len(set))
+	for kind := range set {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// IsSource returns whether a node is a source
+func (pack *Pack) IsSource(_f any) bool {
+	return pack.sources.match(_f)
+}
+
+// IsSink returns whether a node is a sink
+func (pack *Pack) IsSink(_f any) bool {
+	return pack.sinks.match(_f)
+}
+
+// IsSanitizer returns whether a node is a sanitizer
+func (pack *Pack) IsSanitizer(_f any) bool {
+	return pack.sanitizers.match(_f)
+}
+
+// SinkKinds returns the kinds of taint a sink reports, nil means all
+func (pack *Pack) SinkKinds(_f any) []string {
+	return pack.sinks.kinds(_f)
+}
+
+// SanitizerKinds returns the kinds of taint a sanitizer clears, nil means all
+func (pack *Pack) SanitizerKinds(_f any) []string {
+	return pack.sanitizers.kinds(_f)
+}
+
+// Digest returns the hex sha256 of the modules and the rules of the pack
+func (pack *Pack) Digest() string {
+	res, err := json.Marshal(pack)
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(res)
+	return hex.EncodeToString(sum[:])
+}
+
+// IsIntra returns whether a node is from the modules of the pack
+func (pack *Pack) IsIntra(_f any) bool {
+	t, ok := toTarget(_f)
+	if !ok {
+		return false
+	}
+	for _, name := range pack.Modules {
+		if strings.HasPrefix(t.name, name) || strings.HasPrefix(t.name, "("+name) || strings.HasPrefix(t.name, "(*"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// target represents a node with the parts of its canonical name
+type target struct {
+	name     string
+	pkg      string
+	receiver string
+	function string
+	index    int
+	f        *ssa.Function
+}
+
+// toTarget returns the target of a Target or of a canonical name
+func toTarget(_f any) (*target, bool) {
+	t := new(target)
+	switch f := _f.(type) {
+	case Target:
+		t.name, t.index, t.f = f.TargetName(), f.TargetIndex(), f.TargetFunc()
+	case string:
+		t.name, t.index = f, -1
+	default:
+		return nil, false
+	}
+	t.pkg, t.receiver, t.function = splitName(t.name)
+	return t, true
+}
+
+// splitName splits a canonical name to its package, receiver and name
+// e.g. (*database/sql.DB).Exec is database/sql, *database/sql.DB and Exec
+func splitName(name string) (string, string, string) {
+	if strings.HasPrefix(name, "(") {
+		end := strings.LastIndex(name, ").")
+		if end < 0 {
+			return "", "", ""
+		}
+		receiver := name[1:end]
+		typ := strings.TrimPrefix(receiver, "*")
+		if i := strings.Index(typ, "["); i >= 0 {
+			typ = typ[:i]
+		}
+		pkg := ""
+		if i := strings.LastIndex(typ, "."); i >= 0 {
+			pkg = typ[:i]
+		}
+		return pkg, receiver, name[end+2:]
+	}
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if strings.HasPrefix(name, "func") || dot < 0 {
+		return "", "", ""
+	}
+	return name[:slash+1+dot], "", name[slash+2+dot:]
+}
+
+// match returns whether the node meets every criterion of the rule
+func (r *Rule) match(t *target) bool {
+	if r.Function != "" && r.Function != t.name {
+		return false
+	}
+	if r.Package != "" && r.Package != t.pkg {
+		return false
+	}
+	if r.Receiver != "" && r.Receiver != t.receiver {
+		return false
+	}
+	if r.Name != "" && r.Name != t.function {
+		return false
+	}
+	if r.regex != nil && !r.regex.MatchString(t.name) {
+		return false
+	}
+	if len(r.Params) != 0 && !hasParams(t.f, r.Params) {
+		return false
+	}
+	if r.Embeds != "" && !embeds(t.f, r.Embeds) {
+		return false
+	}
+	if len(r.Args) != 0 && t.index >= 0 {
+		for _, arg := range r.Args {
+			if arg == t.index {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// hasParams returns whether the parameters of f, receiver included, have all the types
+func hasParams(f *ssa.Function, params []string) bool {
+	if f == nil {
+		return false
+	}
+	for _, want := range params {
+		found := false
+		for _, param := range f.Params {
+			if param.Type().String() == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// embeds returns whether the receiver of f is a struct with a field of the type
+func embeds(f *ssa.Function, typ string) bool {
+	if f == nil || f.Signature.Recv() == nil {
+		return false
+	}
+	recv := f.Signature.Recv().Type()
+	if pointer, ok := recv.(*types.Pointer); ok {
+		recv = pointer.Elem()
+	}
+	s, ok := recv.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < s.NumFields(); i++ {
+		if s.Field(i).Type().String() == typ {
+			return true
+		}
+	}
+	return false
+}
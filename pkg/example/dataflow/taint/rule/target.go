@@ -0,0 +1,14 @@
+package rule
+
+import "golang.org/x/tools/go/ssa"
+
+// Target represents a node a Ruler decides on
+type Target interface {
+	// TargetName returns the canonical name of the function of the node, e.g. (*database/sql.DB).Exec
+	TargetName() string
+	// TargetIndex returns the position of the node, the receiver of a method is 0
+	// it is -1 if the node stands for every position of its function
+	TargetIndex() int
+	// TargetFunc returns the function of the node, or nil if it has none
+	TargetFunc() *ssa.Function
+}
@@ -6,12 +6,18 @@ import (
 	"go/types"
 	"golang.org/x/tools/go/ssa"
 	"strings"
+	"sync"
 )
 
+// defaultPack returns the built-in rule pack, parsed once, which the DummyRuler takes sanitizers and kinds from
+var defaultPack = sync.OnceValue(rule.DefaultPack)
+
 // DummyRuler is a dummy rule.Ruler used for test
+// its sanitizers and kinds are those of rule.DefaultPack, so both rulers clear the same taint
 type DummyRuler struct {
 	rule.BaseRuler
 	moduleName []string
+	Debug      bool // print the sources it finds
 }
 
 // NewDummyRuler returns a DummyRuler
@@ -21,6 +27,19 @@ func NewDummyRuler(moduleName ...string) *DummyRuler {
 	return dummyRuler
 }
 
+// NewPackRuler returns a *rule.Pack loaded from rule pack files,
+// moduleName is used when none of the files has modules
+func NewPackRuler(moduleName []string, paths ...string) (*rule.Pack, error) {
+	pack, err := rule.LoadPack(paths...)
+	if err != nil {
+		return nil, err
+	}
+	if len(pack.Modules) == 0 {
+		pack.Modules = moduleName
+	}
+	return pack, nil
+}
+
 // IsIntra returns whether a node is from target module
 func (r *DummyRuler) IsIntra(_f any) bool {
 	switch node := (_f).(type) {
@@ -178,6 +197,30 @@ func (r *DummyRuler) IsSink(_f any) bool {
 	return false
 }
 
+// IsSanitizer returns whether a node is a sanitizer of the built-in rule pack
+func (r *DummyRuler) IsSanitizer(_f any) bool {
+	return defaultPack().IsSanitizer(_f)
+}
+
+// SanitizerKinds returns the kinds of taint a sanitizer of the built-in rule pack clears, nil means all
+func (r *DummyRuler) SanitizerKinds(_f any) []string {
+	return defaultPack().SanitizerKinds(_f)
+}
+
+// SinkKinds returns the kinds of taint a sink reports by the built-in rule pack, nil means all
+func (r *DummyRuler) SinkKinds(_f any) []string {
+	return defaultPack().SinkKinds(_f)
+}
+
+// printSource prints a source node in debug mode
+func (r *DummyRuler) printSource(node *Node) {
+	if r.Debug {
+		fmt.Println("is source!")
+		fmt.Println(node.Canonical)
+		fmt.Println()
+	}
+}
+
 // IsSource returns whether a node is a source
 func (r *DummyRuler) IsSource(_f any) bool {
 	source := make(map[string]bool)
@@ -186,9 +229,7 @@ func (r *DummyRuler) IsSource(_f any) bool {
 	case *Node:
 		_, ok := source[node.Canonical]
 		if ok {
-			fmt.Println("is source!")
-			fmt.Println(node.Canonical)
-			fmt.Println()
+			r.printSource(node)
 			return true
 		}
 		if node.Function != nil {
@@ -196,9 +237,7 @@ func (r *DummyRuler) IsSource(_f any) bool {
 			f := node.Function
 			flag = flag || checkTrivalHandler(f) || checkBeegoHandler(f) || checkGinHandler(f)
 			if flag {
-				fmt.Println("is source!")
-				fmt.Println(node.Canonical)
-				fmt.Println()
+				r.printSource(node)
 				return true
 			}
 		}
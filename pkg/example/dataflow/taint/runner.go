@@ -2,7 +2,12 @@ package taint
 
 import (
 	"container/list"
+	"context"
 	"fmt"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/andersen"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/icfg"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/solver"
+	"github.com/zeroy0410/goot/pkg/dataflow/util/worklist"
 	"github.com/zeroy0410/goot/pkg/example/dataflow/taint/rule"
 	"go/types"
 	"golang.org/x/tools/go/callgraph"
@@ -11,26 +16,56 @@ import (
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 	"strings"
+	"time"
 )
 
 // Runner represents a analysis runner
 type Runner struct {
 	ModuleName         string
 	PkgPath            []string
+	Dir                string // directory the PkgPath patterns are loaded in, "" means the current directory
 	UsePointerAnalysis bool
+	CallGraphAlgorithm icfg.Algorithm // algorithm of the call graph used by UsePointerAnalysis
 	Debug              bool
 	InitOnly           bool
 	PassThroughOnly    bool
 	PassThroughSrcPath []string
 	PassThroughDstPath string
 	TaintGraphDstPath  string
+	FindingsDstPath    string // path to write the source to sink findings as json
+	SARIFDstPath       string // path to write the findings as a SARIF log, files under Dir are relative
 	Ruler              rule.Ruler
+	RuleFiles          []string // yaml or json rule packs used when Ruler is nil
 	PersistToNeo4j     bool
 	Neo4jUsername      string
 	Neo4jPassword      string
 	Neo4jURI           string
 	TargetFunc         string
 	PassBack           bool
+	Worklist           worklist.Kind
+	Exceptional        bool
+	FieldDepth         int // k-limit of access paths like t0.Body.x, 0 means the taint of a field is the taint of the whole value
+	// UseAliasAnalysis passes taint stored or loaded through a pointer to the other pointers to the same memory,
+	// which a built-in Andersen analysis of all functions finds
+	UseAliasAnalysis  bool
+	MaxComputations   int
+	FunctionTimeout   time.Duration
+	FunctionMaxMemory uint64
+	Timeout           time.Duration
+	MaxMemory         uint64
+	// Workers is the number of goroutines analyzing functions, 1 or less analyzes them one by one
+	Workers int
+	// CacheDir is a directory caching summaries between runs, unchanged functions are loaded instead of analyzed
+	CacheDir string
+	// CacheHits and CacheMisses are filled by Run with the number of components loaded from and missed in the cache
+	CacheHits   int
+	CacheMisses int
+	// NotConverged is filled by Run with the functions whose analysis stopped before a fixpoint
+	NotConverged []NotConverged
+	// Graph is filled by Run with the taint graph of the analysis
+	Graph *TaintGraph
+	// Findings is filled by Run with the paths from sources to sinks in Graph
+	Findings []*Finding
 }
 
 func getTypes(t types.Type) (types.Type, string) {
@@ -107,17 +142,32 @@ func PrintAssertionsInfo(resultTypes map[*ssa.TypeAssert][]types.Type) {
 
 // NewRunner returns a *taint.Runner
 func NewRunner(PkgPath ...string) *Runner {
-	return &Runner{PkgPath: PkgPath, ModuleName: "",
+	return &Runner{PkgPath: PkgPath, ModuleName: "", Dir: "", CallGraphAlgorithm: icfg.VTA,
 		PassThroughSrcPath: nil, PassThroughDstPath: "",
-		TaintGraphDstPath: "", Ruler: nil,
+		TaintGraphDstPath: "", FindingsDstPath: "", SARIFDstPath: "", Ruler: nil,
 		Debug: false, InitOnly: false, PassThroughOnly: false,
 		PersistToNeo4j: false, Neo4jURI: "", Neo4jUsername: "", Neo4jPassword: "",
 		TargetFunc: "", PassBack: false,
-		UsePointerAnalysis: false}
+		UsePointerAnalysis: false, Worklist: worklist.FIFO, Exceptional: false, FieldDepth: 0, UseAliasAnalysis: false,
+		MaxComputations: DefaultMaxComputations, FunctionTimeout: 0, FunctionMaxMemory: 0,
+		Timeout: 0, MaxMemory: 0, Workers: 1, CacheDir: ""}
 }
 
 // Run kick off an analysis
 func (r *Runner) Run() error {
+	return r.RunContext(context.Background())
+}
+
+// RunContext kick off an analysis which stops when ctx is done or Timeout passes
+// functions not analyzed to a fixpoint are recorded in NotConverged,
+// and the error of ctx is returned if the run was cut short
+func (r *Runner) RunContext(ctx context.Context) error {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
 	mode := packages.NeedName |
 		packages.NeedFiles |
 		packages.NeedCompiledGoFiles |
@@ -127,7 +177,7 @@ func (r *Runner) Run() error {
 		packages.NeedTypesSizes |
 		packages.NeedTypes |
 		packages.NeedDeps
-	cfg := &packages.Config{Mode: mode, Dir: "C:/Users/zeroy/Documents/Code/goot/cmd/taintanalysis/nilaway/"}
+	cfg := &packages.Config{Mode: mode, Context: ctx, Dir: r.Dir}
 	initial, err := packages.Load(cfg, r.PkgPath...)
 
 	if err != nil {
@@ -140,37 +190,68 @@ func (r *Runner) Run() error {
 
 	funcs := ssautil.AllFunctions(prog)
 
-	interfaceHierarchy := NewInterfaceHierarchy(&funcs)
+	interfaceHierarchy := icfg.NewInterfaceHierarchy(&funcs)
 
 	var cg *callgraph.Graph
 	if r.UsePointerAnalysis {
-		mainFuncs := make([]*ssa.Function, 0)
-		for _, pkg := range initial {
-			mainPkg := prog.Package(pkg.Types)
-			if mainPkg != nil && mainPkg.Pkg.Name() == "main" && mainPkg.Func("main") != nil {
-				mainFuncs = append(mainFuncs, mainPkg.Func("main"))
+		// only RTA starts from main functions, other algorithms also analyze libraries
+		if r.CallGraphAlgorithm == icfg.RTA {
+			mainFuncs := make([]*ssa.Function, 0)
+			for _, pkg := range initial {
+				mainPkg := prog.Package(pkg.Types)
+				if mainPkg != nil && mainPkg.Pkg.Name() == "main" && mainPkg.Func("main") != nil {
+					mainFuncs = append(mainFuncs, mainPkg.Func("main"))
+				}
+			}
+			if len(mainFuncs) == 0 {
+				return new(icfg.NoMainError)
 			}
-		}
-		if len(mainFuncs) == 0 {
-			return new(NoMainPkgError)
 		}
 
-		result := vta.CallGraph(ssautil.AllFunctions(prog), nil)
-		resultTypes := vta.GetTypeAsserts(ssautil.AllFunctions(prog), nil)
-		PrintAssertionsInfo(resultTypes)
+		if r.CallGraphAlgorithm == icfg.VTA {
+			result := vta.CallGraph(ssautil.AllFunctions(prog), nil)
+			if r.Debug {
+				resultTypes := vta.GetTypeAsserts(ssautil.AllFunctions(prog), nil)
+				PrintAssertionsInfo(resultTypes)
+			}
 
-		cg = result
-		cg.DeleteSyntheticNodes()
+			cg = result
+			cg.DeleteSyntheticNodes()
+		} else {
+			g, err := icfg.Build(prog, r.CallGraphAlgorithm)
+			if err != nil {
+				return err
+			}
+			cg = g.CallGraph
+		}
 	}
 
 	var ruler rule.Ruler
 	if r.Ruler != nil {
 		ruler = r.Ruler
+	} else if len(r.RuleFiles) != 0 {
+		pack, err := NewPackRuler([]string{r.ModuleName}, r.RuleFiles...)
+		if err != nil {
+			return err
+		}
+		ruler = pack
 	} else {
-		ruler = NewDummyRuler(r.ModuleName)
+		dummy := NewDummyRuler(r.ModuleName)
+		dummy.Debug = r.Debug
+		ruler = dummy
 	}
 	taintGraph := NewTaintGraph(&funcs, ruler)
 
+	var aliases *andersen.Result
+	if r.UseAliasAnalysis {
+		// 没有指针分析时用接口层次构建的调用图解析动态调用
+		aliasGraph := cg
+		if aliasGraph == nil {
+			aliasGraph = interfaceHierarchy.CallGraph(funcs)
+		}
+		aliases = andersen.Analyze(funcs, aliasGraph)
+	}
+
 	passThroughContainter := make(map[string]*PassThroughCache)
 	if r.PassThroughSrcPath != nil {
 		err := FetchPassThrough(&passThroughContainter, r.PassThroughSrcPath)
@@ -181,6 +262,7 @@ func (r *Runner) Run() error {
 
 	initMap := make(map[string]*ssa.Function)
 	history := make(map[string]bool)
+	notConverged := make([]NotConverged, 0)
 
 	c := &TaintConfig{PassThroughContainer: &passThroughContainter,
 		InitMap:            &initMap,
@@ -190,26 +272,67 @@ func (r *Runner) Run() error {
 		TaintGraph:         taintGraph,
 		UsePointerAnalysis: r.UsePointerAnalysis,
 		CallGraph:          cg,
+		CallGraphAlgorithm: r.CallGraphAlgorithm,
 		Ruler:              ruler,
 		PassThroughOnly:    r.PassThroughOnly,
 		Debug:              r.Debug,
 		TargetFunc:         r.TargetFunc,
-		PassBack:           r.PassBack}
+		PassBack:           r.PassBack,
+		Worklist:           r.Worklist,
+		Exceptional:        r.Exceptional,
+		FieldDepth:         r.FieldDepth,
+		Aliases:            aliases,
+		Context:            ctx,
+		MaxComputations:    r.MaxComputations,
+		Budget:             solver.Budget{Timeout: r.FunctionTimeout, MaxMemory: r.FunctionMaxMemory},
+		MaxMemory:          r.MaxMemory,
+		NotConverged:       &notConverged}
 
-	for f := range funcs {
+	sorted := sortedFuncs(funcs)
+	for _, f := range sorted {
 		if f.Name() == "init" {
 			Run(f, c)
 		}
 	}
 
 	if !r.InitOnly {
-		for f := range funcs {
-			if f.String() != "init" {
-				if r.TargetFunc != "" && f.String() != r.TargetFunc {
-					continue
+		if (r.Workers > 1 || r.CacheDir != "") && r.TargetFunc == "" {
+			// 按调用图自底向上分批并行分析，没有指针分析时用接口层次构建调用图
+			var cache *summaryCache
+			if r.CacheDir != "" {
+				cache, err = newSummaryCache(r.CacheDir, r.Debug)
+				if err != nil {
+					return err
 				}
-				Run(f, c)
 			}
+			scheduleGraph := cg
+			if scheduleGraph == nil {
+				scheduleGraph = interfaceHierarchy.CallGraph(funcs)
+			}
+			waves := schedule(sorted, scheduleGraph)
+			runParallel(c, waves, r.Workers, cache)
+			if cache != nil {
+				r.CacheHits = int(cache.hits.Load())
+				r.CacheMisses = int(cache.misses.Load())
+			}
+		} else {
+			for _, f := range sorted {
+				if f.String() != "init" {
+					if r.TargetFunc != "" && f.String() != r.TargetFunc {
+						continue
+					}
+					Run(f, c)
+				}
+			}
+		}
+	}
+
+	r.NotConverged = notConverged
+	r.Graph = taintGraph
+	r.Findings = taintGraph.Findings(ruler)
+	if r.Debug {
+		for _, n := range notConverged {
+			fmt.Println("not converged:", n.Function, "because of", n.Reason, "after", n.Computations, "computations")
 		}
 	}
 
@@ -219,8 +342,20 @@ func (r *Runner) Run() error {
 	if r.TaintGraphDstPath != "" {
 		PersistTaintGraph(taintGraph.Edges, r.TaintGraphDstPath)
 	}
+	if r.FindingsDstPath != "" {
+		PersistFindings(r.Findings, r.FindingsDstPath)
+	}
+	if r.SARIFDstPath != "" {
+		root := r.Dir
+		if root == "" {
+			root = "."
+		}
+		if err := PersistSARIF(r.Findings, r.SARIFDstPath, root); err != nil {
+			return err
+		}
+	}
 	if !r.PassThroughOnly && r.PersistToNeo4j {
 		PersistToNeo4j(taintGraph.Nodes, taintGraph.Edges, r.Neo4jURI, r.Neo4jUsername, r.Neo4jPassword)
 	}
-	return nil
+	return ctx.Err()
 }
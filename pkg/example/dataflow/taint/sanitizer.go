@@ -0,0 +1,104 @@
+package taint
+
+import (
+	"go/token"
+	"strconv"
+
+	"github.com/zeroy0410/goot/pkg/example/dataflow/taint/rule"
+	"golang.org/x/tools/go/ssa"
+)
+
+// sanitizerKinds returns whether the i'th position of f is a sanitizer, and the kinds it clears, nil means all
+func sanitizerKinds(ruler rule.Ruler, f *ssa.Function, i int) (bool, []string) {
+	node := &Node{Function: f, Canonical: f.String(), Index: i, IsStatic: true}
+	if !ruler.IsSanitizer(node) {
+		return false, nil
+	}
+	if kindRuler, ok := ruler.(rule.KindRuler); ok {
+		return true, kindRuler.SanitizerKinds(node)
+	}
+	return true, nil
+}
+
+// sanitizedSink returns whether node is a sink and taint sanitized for kinds does not reach it,
+// that is the sink has kinds and kinds cover all of them
+func sanitizedSink(ruler rule.Ruler, node *Node, kinds []string) bool {
+	if len(kinds) == 0 || !ruler.IsSink(node) {
+		return false
+	}
+	kindRuler, ok := ruler.(rule.KindRuler)
+	if !ok {
+		return false
+	}
+	sinkKinds := kindRuler.SinkKinds(node)
+	if len(sinkKinds) == 0 {
+		return false
+	}
+	return len(intersectKinds(sinkKinds, kinds)) == len(sinkKinds)
+}
+
+// passSanitizerTaint passes taint through a call to a sanitizer and returns whether f is one
+// every result gets the taint of all args, and the taint of the args a sanitizer covers is sanitized
+func (s *TaintSwitcher) passSanitizerTaint(f *ssa.Function, inst *ssa.Call) bool {
+	ruler := s.taintAnalysis.config.Ruler
+	sanitized := make([]*TaintWrapper, 0, len(inst.Call.Args))
+	sanitizer := false
+	for i, arg := range inst.Call.Args {
+		ok, kinds := sanitizerKinds(ruler, f, i)
+		if ok {
+			sanitizer = true
+			sanitized = append(sanitized, GetTaintWrapper(s.outMap, arg.Name()).Sanitize(kinds))
+		} else {
+			sanitized = append(sanitized, GetTaintWrapper(s.outMap, arg.Name()))
+		}
+	}
+	if !sanitizer {
+		return false
+	}
+	n := f.Signature.Results().Len()
+	for i := 0; i < n; i++ {
+		newTaint := NewTaintWrapper()
+		for _, w := range sanitized {
+			for taint := range *w.innerTaint {
+				newTaint.AddTaint(taint)
+			}
+		}
+		if n == 1 {
+			SetTaintWrapper(s.outMap, inst.Name(), newTaint)
+		} else {
+			SetTaintWrapper(s.outMap, inst.Name()+"."+strconv.Itoa(i), newTaint)
+		}
+	}
+	return true
+}
+
+// sanitizeBranch sanitizes the args of a sanitizer used as a condition on the branch where it returns true,
+// e.g. x in the then branch of if isSafe(x) { ... } or in the else branch of if !isSafe(x) { ... }
+func (a *TaintAnalysis) sanitizeBranch(inst *ssa.If, succ ssa.Instruction, outMap *map[any]any) {
+	cond := inst.Cond
+	want := true
+	if op, ok := cond.(*ssa.UnOp); ok && op.Op == token.NOT {
+		cond = op.X
+		want = false
+	}
+	call, ok := cond.(*ssa.Call)
+	if !ok {
+		return
+	}
+	f := call.Call.StaticCallee()
+	then, els := inst.Block().Succs[0], inst.Block().Succs[1]
+	if f == nil || then == els || (succ.Block() == then) != want {
+		return
+	}
+	for i, arg := range call.Call.Args {
+		ok, kinds := sanitizerKinds(a.config.Ruler, f, i)
+		if !ok {
+			continue
+		}
+		SetTaintWrapper(outMap, arg.Name(), GetTaintWrapper(outMap, arg.Name()).Sanitize(kinds))
+		if op, ok := arg.(*ssa.UnOp); ok && op.Op == token.MUL {
+			// the arg is loaded from a pointer, later loads read the pointer's taint
+			SetTaintWrapper(outMap, op.X.Name(), GetTaintWrapper(outMap, op.X.Name()).Sanitize(kinds))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package taint
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSanitizerKinds(t *testing.T) {
+	r := newGoldenRunner(t, "sanitizer")
+	runGolden(t, r)
+	// QuotedQuery, WrappedQuery and CheckedExec are sanitized for every kind of their sinks
+	want := []string{
+		"sanitizer.QuotedExec -> lib.Exec @ sanitizer/sanitizer.go:21:10",
+		"sanitizer.QuotedRender -> lib.Render @ sanitizer/sanitizer.go:16:12",
+		"sanitizer.UncheckedExec -> lib.Exec @ sanitizer/sanitizer.go:43:10",
+	}
+	if got := findingsOf(r); !slices.Equal(got, want) {
+		t.Errorf("findings %q, want %q", got, want)
+	}
+}
+
+func TestDummyRulerSanitizers(t *testing.T) {
+	r := NewDummyRuler()
+	for _, tc := range []struct {
+		name  string
+		kinds []string
+	}{
+		{"strconv.Atoi", nil},
+		{"html.EscapeString", []string{"xss"}},
+		{"net/url.QueryEscape", []string{"ssrf"}},
+		{"path/filepath.Base", []string{"traversal"}},
+	} {
+		node := &Node{Canonical: tc.name, IsStatic: true}
+		if !r.IsSanitizer(node) {
+			t.Errorf("%s is not a sanitizer", tc.name)
+		}
+		if got := r.SanitizerKinds(node); !slices.Equal(got, tc.kinds) {
+			t.Errorf("%s clears %v, want %v", tc.name, got, tc.kinds)
+		}
+	}
+	if r.IsSanitizer(&Node{Canonical: "os/exec.Command", IsStatic: true}) {
+		t.Error("os/exec.Command is a sanitizer")
+	}
+}
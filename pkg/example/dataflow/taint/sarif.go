@@ -0,0 +1,229 @@
+package taint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sarifVersion is the version of SARIF written by WriteSARIF
+const sarifVersion = "2.1.0"
+
+// sarifSchema is the schema of SARIF 2.1.0
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifFingerprint is the key of the fingerprint of a finding in partialFingerprints
+const sarifFingerprint = "gootTaintPath/v1"
+
+// sarifRootBase is the uriBaseId of files under the root directory
+const sarifRootBase = "SRCROOT"
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	CodeFlows           []sarifCodeFlow   `json:"codeFlows"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifCodeFlow struct {
+	ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+}
+
+type sarifThreadFlow struct {
+	Locations []sarifThreadFlowLocation `json:"locations"`
+}
+
+type sarifThreadFlowLocation struct {
+	Location sarifLocation `json:"location"`
+}
+
+// WriteSARIF writes findings as a SARIF 2.1.0 log
+// every kind of sink is a rule, a finding's location is its sink call and its code flow is its path,
+// files under root are relative to the SRCROOT base, an empty root writes absolute file uris
+// the fingerprint of a finding hashes its rule and the files, functions and calls of its path, but not lines,
+// so it stays the same when code around the path moves
+func WriteSARIF(w io.Writer, findings []*Finding, root string) error {
+	run := sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: "goot",
+		InformationURI: "https://github.com/zeroy0410/goot", Rules: make([]sarifRule, 0)}},
+		Results: make([]sarifResult, 0, len(findings))}
+	if root != "" {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return err
+		}
+		root = abs
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{sarifRootBase: {URI: fileURI(root) + "/"}}
+	}
+
+	ruleIndex := make(map[string]int)
+	ids := make([]string, 0)
+	for _, finding := range findings {
+		id := sarifRuleID(finding)
+		if _, ok := ruleIndex[id]; !ok {
+			ruleIndex[id] = 0
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		ruleIndex[id] = i
+		kind := strings.TrimPrefix(id, "taint/")
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id, Name: kind,
+			ShortDescription: sarifMessage{Text: "taint flows from a source to a " + kind + " sink"}})
+	}
+
+	for _, finding := range findings {
+		id := sarifRuleID(finding)
+		flow := sarifThreadFlow{Locations: make([]sarifThreadFlowLocation, 0, len(finding.Path))}
+		for _, edge := range finding.Path {
+			location := sarifLocation{PhysicalLocation: physicalLocation(edge.Position, root),
+				Message: &sarifMessage{Text: edge.Instruction}}
+			flow.Locations = append(flow.Locations, sarifThreadFlowLocation{Location: location})
+		}
+		sink := finding.Path[len(finding.Path)-1]
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    id,
+			RuleIndex: ruleIndex[id],
+			Level:     "warning",
+			Message: sarifMessage{Text: fmt.Sprintf("taint from position %d of source %s reaches position %d of sink %s",
+				finding.SourceIndex, finding.Source, finding.SinkIndex, finding.Sink)},
+			Locations:           []sarifLocation{{PhysicalLocation: physicalLocation(sink.Position, root)}},
+			CodeFlows:           []sarifCodeFlow{{ThreadFlows: []sarifThreadFlow{flow}}},
+			PartialFingerprints: map[string]string{sarifFingerprint: fingerprint(id, finding, root)},
+		})
+	}
+
+	log := sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}}
+	res, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(res)
+	return err
+}
+
+// PersistSARIF stores findings as a SARIF log to target destination, files under root are relative
+func PersistSARIF(findings []*Finding, dst string, root string) error {
+	f, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WriteSARIF(f, findings, root)
+}
+
+// sarifRuleID returns the rule of a finding, which is the kinds of its sink
+func sarifRuleID(finding *Finding) string {
+	if len(finding.Kinds) == 0 {
+		return "taint/sink"
+	}
+	return "taint/" + strings.Join(finding.Kinds, "+")
+}
+
+// physicalLocation returns the location of a position, the path is relative to root if it is under root
+func physicalLocation(position token.Position, root string) sarifPhysicalLocation {
+	location := sarifPhysicalLocation{ArtifactLocation: artifactLocation(position.Filename, root)}
+	if position.Line > 0 {
+		location.Region = &sarifRegion{StartLine: position.Line, StartColumn: position.Column}
+	}
+	return location
+}
+
+// artifactLocation returns the location of a file, relative to root if it is under root
+func artifactLocation(filename string, root string) sarifArtifactLocation {
+	if root != "" {
+		if rel, err := filepath.Rel(root, filename); err == nil && !strings.HasPrefix(rel, "..") {
+			return sarifArtifactLocation{URI: (&url.URL{Path: filepath.ToSlash(rel)}).String(), URIBaseID: sarifRootBase}
+		}
+	}
+	return sarifArtifactLocation{URI: fileURI(filename)}
+}
+
+// fileURI returns the file uri of an absolute path
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// windows paths like C:/a start without a slash
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// fingerprint returns a stable hash of a finding, made of its rule, its source, its sink
+// and the files, functions and calls of its path
+// a call is told apart from other calls of its callee in the same function by its column and its ordinal,
+// so calls of a sink at two call sites get different fingerprints whatever order findings are written in
+func fingerprint(id string, finding *Finding, root string) string {
+	h := sha256.New()
+	fmt.Fprintln(h, id, finding.Source, finding.SourceIndex, finding.Sink, finding.SinkIndex)
+	for _, edge := range finding.Path {
+		fmt.Fprintln(h, artifactLocation(edge.Position.Filename, root).URI,
+			edge.From, strconv.Itoa(edge.FromIndex), edge.To, strconv.Itoa(edge.ToIndex),
+			strconv.Itoa(edge.Position.Column), strconv.Itoa(edge.Call))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
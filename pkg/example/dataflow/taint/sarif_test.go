@@ -0,0 +1,126 @@
+package taint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sarifOf returns the SARIF log of findings with root as SRCROOT, parsed back
+func sarifOf(t *testing.T, findings []*Finding, root string) *sarifLog {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, findings, root); err != nil {
+		t.Fatal(err)
+	}
+	log := new(sarifLog)
+	if err := json.Unmarshal(buf.Bytes(), log); err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func TestWriteSARIF(t *testing.T) {
+	r := newGoldenRunner(t, "findings")
+	runGolden(t, r)
+	log := sarifOf(t, r.Findings, r.Dir)
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version %s with %d runs, want 2.1.0 with 1 run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	// the uri of SRCROOT is the only absolute path
+	if got, want := run.OriginalURIBaseIDs[sarifRootBase].URI, fileURI(r.Dir)+"/"; got != want {
+		t.Errorf("%s is %s, want %s", sarifRootBase, got, want)
+	}
+	rules := make([]string, 0)
+	for _, rule := range run.Tool.Driver.Rules {
+		rules = append(rules, rule.ID)
+	}
+	if want := []string{"taint/sink", "taint/sqli"}; !slices.Equal(rules, want) {
+		t.Errorf("rules %v, want %v", rules, want)
+	}
+	results := make([]string, 0)
+	for _, result := range run.Results {
+		location := result.Locations[0].PhysicalLocation
+		results = append(results, fmt.Sprintf("%s @ %s:%d:%d", result.RuleID, location.ArtifactLocation.URI,
+			location.Region.StartLine, location.Region.StartColumn))
+	}
+	want := []string{
+		"taint/sink @ findings/findings.go:6:30",
+		"taint/sqli @ findings/findings.go:10:11",
+		"taint/sqli @ findings/findings.go:12:12",
+	}
+	if !slices.Equal(results, want) {
+		t.Errorf("results %q, want %q", results, want)
+	}
+	if len(run.Results) != len(r.Findings) {
+		t.Fatalf("%d results of %d findings", len(run.Results), len(r.Findings))
+	}
+	prints := make(map[string]bool)
+	for i, result := range run.Results {
+		if result.RuleIndex >= len(run.Tool.Driver.Rules) || run.Tool.Driver.Rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("result %d: rule %s is not at index %d", i, result.RuleID, result.RuleIndex)
+		}
+		location := result.Locations[0].PhysicalLocation.ArtifactLocation
+		if location.URIBaseID != sarifRootBase || strings.HasPrefix(location.URI, "file:") {
+			t.Errorf("result %d: location %+v is not relative to %s", i, location, sarifRootBase)
+		}
+		if n := len(result.CodeFlows[0].ThreadFlows[0].Locations); n != len(r.Findings[i].Path) {
+			t.Errorf("result %d: code flow has %d locations, path has %d edges", i, n, len(r.Findings[i].Path))
+		}
+		partial := result.PartialFingerprints[sarifFingerprint]
+		if prints[partial] {
+			t.Errorf("result %d: fingerprint %s is not unique", i, partial)
+		}
+		prints[partial] = true
+	}
+}
+
+func TestSARIFFingerprintIgnoresLines(t *testing.T) {
+	r := newGoldenRunner(t, "findings")
+	runGolden(t, r)
+	before := sarifOf(t, r.Findings, r.Dir)
+	// code above the paths moves them down
+	for _, finding := range r.Findings {
+		for _, edge := range finding.Path {
+			edge.Position.Line += 3
+			edge.Position.Offset += 30
+		}
+	}
+	after := sarifOf(t, r.Findings, r.Dir)
+	for i := range before.Runs[0].Results {
+		want := before.Runs[0].Results[i].PartialFingerprints[sarifFingerprint]
+		if got := after.Runs[0].Results[i].PartialFingerprints[sarifFingerprint]; got != want {
+			t.Errorf("result %d: fingerprint %s changed to %s when lines moved", i, want, got)
+		}
+	}
+}
+
+func TestSARIFFingerprintIgnoresOrder(t *testing.T) {
+	r := newGoldenRunner(t, "findings")
+	runGolden(t, r)
+	before := sarifOf(t, r.Findings, r.Dir)
+	reversed := slices.Clone(r.Findings)
+	slices.Reverse(reversed)
+	after := sarifOf(t, reversed, r.Dir)
+	n := len(before.Runs[0].Results)
+	for i := range before.Runs[0].Results {
+		want := before.Runs[0].Results[i].PartialFingerprints[sarifFingerprint]
+		if got := after.Runs[0].Results[n-1-i].PartialFingerprints[sarifFingerprint]; got != want {
+			t.Errorf("result %d: fingerprint %s changed to %s when findings were reordered", i, want, got)
+		}
+	}
+	// the second call of lib.Query in Handler is the second call of its callee
+	calls := make([]int, 0)
+	for _, finding := range r.Findings {
+		if finding.Sink == "example.com/golden/lib.Query" {
+			calls = append(calls, finding.Path[len(finding.Path)-1].Call)
+		}
+	}
+	if !slices.Equal(calls, []int{0, 1}) {
+		t.Errorf("calls of lib.Query are %v, want [0 1]", calls)
+	}
+}
@@ -0,0 +1,141 @@
+package taint
+
+import (
+	"container/list"
+	"sort"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// overlay represents the writes of a task of a parallel run
+// the shared stores are read only while tasks run, so a task keeps its writes here,
+// and they are committed after its wave in a fixed order
+type overlay struct {
+	passThrough      map[string]*PassThroughCache
+	passThroughOrder []string
+	initMap          map[string]*ssa.Function
+	edges            []pendingEdge
+	notConverged     []NotConverged
+}
+
+// pendingEdge represents an edge to be added to the TaintGraph
+// node is the prototype of the target node, nil if the target must already exist
+type pendingEdge struct {
+	key  string
+	key2 string
+	edge *Edge
+	node *Node
+}
+
+// newOverlay returns an empty overlay
+func newOverlay() *overlay {
+	o := new(overlay)
+	o.passThrough = make(map[string]*PassThroughCache)
+	o.passThroughOrder = make([]string, 0)
+	o.initMap = make(map[string]*ssa.Function)
+	o.edges = make([]pendingEdge, 0)
+	o.notConverged = make([]NotConverged, 0)
+	return o
+}
+
+// getPassThrough returns the passthrough of a function
+func (c *TaintConfig) getPassThrough(name string) (*PassThroughCache, bool) {
+	if c.local != nil {
+		if p, ok := c.local.passThrough[name]; ok {
+			return p, true
+		}
+	}
+	p, ok := (*c.PassThroughContainer)[name]
+	return p, ok
+}
+
+// setPassThrough saves the passthrough of a function
+func (c *TaintConfig) setPassThrough(name string, p *PassThroughCache) {
+	if c.local == nil {
+		(*c.PassThroughContainer)[name] = p
+		return
+	}
+	if _, ok := c.local.passThrough[name]; !ok {
+		c.local.passThroughOrder = append(c.local.passThroughOrder, name)
+	}
+	c.local.passThrough[name] = p
+}
+
+// getInit returns the anonymous function stored to a global
+func (c *TaintConfig) getInit(name string) (*ssa.Function, bool) {
+	if c.local != nil {
+		if f, ok := c.local.initMap[name]; ok {
+			return f, true
+		}
+	}
+	f, ok := (*c.InitMap)[name]
+	return f, ok
+}
+
+// setInit records the anonymous function stored to a global
+func (c *TaintConfig) setInit(name string, f *ssa.Function) {
+	if c.local == nil {
+		(*c.InitMap)[name] = f
+		return
+	}
+	c.local.initMap[name] = f
+}
+
+// addEdge adds an edge to the TaintGraph, a missing target node is created from the prototype node
+func (c *TaintConfig) addEdge(key string, key2 string, edge *Edge, node *Node) {
+	if c.local == nil {
+		c.TaintGraph.AddEdge(key, key2, edge, c.newNode(node))
+		return
+	}
+	c.local.edges = append(c.local.edges, pendingEdge{key: key, key2: key2, edge: edge, node: node})
+}
+
+// newNode returns a function creating a node like the prototype, with properties decided by the ruler
+func (c *TaintConfig) newNode(node *Node) func() *Node {
+	if node == nil {
+		return nil
+	}
+	return func() *Node {
+		node2 := &Node{Canonical: node.Canonical, Index: node.Index, Position: node.Position, Out: make([]*Edge, 0), In: make([]*Edge, 0),
+			IsSignature: node.IsSignature, IsMethod: node.IsMethod, IsStatic: node.IsStatic}
+		decidePropertry(node2, c.Ruler)
+		return node2
+	}
+}
+
+// fork returns a config for a task of a parallel run, which shares the stores of c for reading
+// and has its own overlay, history and call stack
+func (c *TaintConfig) fork() *TaintConfig {
+	task := *c
+	task.local = newOverlay()
+	history := make(map[string]bool)
+	task.History = &history
+	task.CallStack = list.New().Init()
+	task.NotConverged = &task.local.notConverged
+	return &task
+}
+
+// commit writes the overlay of a task to the shared stores
+// a passthrough already in the container is kept, so the first task to commit it wins
+func (c *TaintConfig) commit(task *TaintConfig) {
+	o := task.local
+	for _, name := range o.passThroughOrder {
+		if _, ok := (*c.PassThroughContainer)[name]; !ok {
+			(*c.PassThroughContainer)[name] = o.passThrough[name]
+		}
+	}
+	names := make([]string, 0, len(o.initMap))
+	for name := range o.initMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		(*c.InitMap)[name] = o.initMap[name]
+	}
+	for _, e := range o.edges {
+		c.TaintGraph.AddEdge(e.key, e.key2, e.edge, c.newNode(e.node))
+	}
+	if c.NotConverged != nil {
+		*c.NotConverged = append(*c.NotConverged, o.notConverged...)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/zeroy0410/goot/pkg/dataflow/golang/switcher"
+	"github.com/zeroy0410/goot/pkg/dataflow/toolkits/graph"
 	"golang.org/x/tools/go/ssa"
 )
 
@@ -32,8 +33,6 @@ func (s *TaintSwitcher) CaseBinOp(inst *ssa.BinOp) {
 // CaseCall accepts a Call instruction
 func (s *TaintSwitcher) CaseCall(inst *ssa.Call) {
 	c := s.taintAnalysis.config
-	container := c.PassThroughContainer
-	init := s.taintAnalysis.config.InitMap
 	// try to use pointer analysis to select callee
 	callGraph := s.taintAnalysis.config.CallGraph
 	if c.UsePointerAnalysis && inst.Common().StaticCallee() == nil {
@@ -175,7 +174,7 @@ func (s *TaintSwitcher) CaseCall(inst *ssa.Call) {
 			}
 		case *ssa.Global:
 			// its inst.X can be a global anonymous function or a global anonymous interface
-			f, ok := (*init)[x.String()]
+			f, ok := c.getInit(x.String())
 			if ok {
 				// anonymous function that has been declared in source
 				s.passCallTaint(f, inst)
@@ -203,7 +202,7 @@ func (s *TaintSwitcher) CaseCall(inst *ssa.Call) {
 						if f, ok := store.Val.(*ssa.Function); ok {
 							// if a function stored to inst.X
 							ref = ok
-							_, ok = (*container)[f.String()]
+							_, ok = c.getPassThrough(f.String())
 							if !ok {
 								Run(f, c)
 							}
@@ -212,7 +211,7 @@ func (s *TaintSwitcher) CaseCall(inst *ssa.Call) {
 							if f, ok := closure.Fn.(*ssa.Function); ok {
 								// if a closure stored to inst.X, retrive its Fn
 								ref = ok
-								_, ok = (*container)[f.String()]
+								_, ok = c.getPassThrough(f.String())
 								if !ok {
 									Run(f, c)
 								}
@@ -433,19 +432,19 @@ func (s *TaintSwitcher) CaseCall(inst *ssa.Call) {
 // CaseChangeInterface accepts a ChangeInterface instruction
 func (s *TaintSwitcher) CaseChangeInterface(inst *ssa.ChangeInterface) {
 	// we drop *ssa.Global, *ssa.FreeVar and *ssa.Const
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	s.passTaint(inst.Name(), inst.X.Name())
 }
 
 // CaseChangeType accepts a ChangeType instruction
 func (s *TaintSwitcher) CaseChangeType(inst *ssa.ChangeType) {
 	// we drop *ssa.Global, *ssa.FreeVar and *ssa.Const
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	s.passTaint(inst.Name(), inst.X.Name())
 }
 
 // CaseConvert accepts a Convert instruction
 func (s *TaintSwitcher) CaseConvert(inst *ssa.Convert) {
 	// skip *ssa.Global, *ssa.FreeVar and *ssa.Const
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	s.passTaint(inst.Name(), inst.X.Name())
 }
 
 // CaseExtract accepts a Extract instruction
@@ -453,31 +452,41 @@ func (s *TaintSwitcher) CaseExtract(inst *ssa.Extract) {
 	// mark the variables as "inst.Tuple.Name().i"
 	// e.g. t1.0, t3.2
 	mark := inst.Tuple.Name() + "." + strconv.Itoa(inst.Index)
-	PassTaint(s.outMap, inst.Name(), mark)
+	s.passTaint(inst.Name(), mark)
 }
 
 // CaseField accepts a Field instruction
 func (s *TaintSwitcher) CaseField(inst *ssa.Field) {
 	// we drop *ssa.Global, *ssa.FreeVar and *ssa.Const
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	if field, ok := s.field(inst.X.Type(), inst.Field); ok {
+		// only the taint of the field and of the whole struct reaches it
+		s.readField(inst.Name(), inst.X.Name(), field)
+	} else {
+		s.passTaint(inst.Name(), inst.X.Name())
+	}
 }
 
 // CaseFieldAddr accepts a FieldAddr instruction
 func (s *TaintSwitcher) CaseFieldAddr(inst *ssa.FieldAddr) {
 	// we drop *ssa.Global, *ssa.FreeVar and *ssa.Const
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	if field, ok := s.field(inst.X.Type(), inst.Field); ok {
+		// only the taint of the field and of the whole struct reaches it
+		s.readField(inst.Name(), inst.X.Name(), field)
+	} else {
+		s.passTaint(inst.Name(), inst.X.Name())
+	}
 }
 
 // CaseIndex accepts an Index instruction
 func (s *TaintSwitcher) CaseIndex(inst *ssa.Index) {
 	// we drop *ssa.Global, *ssa.FreeVar and *ssa.Const
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	s.passTaint(inst.Name(), inst.X.Name())
 }
 
 // CaseIndexAddr accepts an IndexAddr instruction
 func (s *TaintSwitcher) CaseIndexAddr(inst *ssa.IndexAddr) {
 	// we drop *ssa.Global, *ssa.FreeVar and *ssa.Const
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	s.passTaint(inst.Name(), inst.X.Name())
 }
 
 // CaseLookup accepts a Lookup instruction
@@ -485,10 +494,10 @@ func (s *TaintSwitcher) CaseLookup(inst *ssa.Lookup) {
 	// pass taint in index and map
 	if inst.CommaOk {
 		// if needs an ok, mark two variables, and the first one inherits taint
-		PassTaint(s.outMap, inst.Name()+".0", inst.Index.Name(), inst.X.Name())
+		s.passTaint(inst.Name()+".0", inst.Index.Name(), inst.X.Name())
 		GetTaintWrapper(s.outMap, inst.Name()+".1")
 	} else {
-		PassTaint(s.outMap, inst.Name(), inst.Index.Name(), inst.X.Name())
+		s.passTaint(inst.Name(), inst.Index.Name(), inst.X.Name())
 	}
 }
 
@@ -505,7 +514,7 @@ func (s *TaintSwitcher) CaseMakeChan(inst *ssa.MakeChan) {
 // CaseMakeInterface accepts a MakeInterface instruction
 func (s *TaintSwitcher) CaseMakeInterface(inst *ssa.MakeInterface) {
 	// we drop *ssa.Global, *ssa.FreeVar and *ssa.Const
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	s.passTaint(inst.Name(), inst.X.Name())
 }
 
 // CaseMakeMap accepts a MakeMap instruction
@@ -522,14 +531,14 @@ func (s *TaintSwitcher) CaseMakeSlice(inst *ssa.MakeSlice) {
 func (s *TaintSwitcher) CaseNext(inst *ssa.Next) {
 	// mark three variables, and the second and the third inherits taint
 	GetTaintWrapper(s.outMap, inst.Name()+".0")
-	PassTaint(s.outMap, inst.Name()+".1", inst.Iter.Name())
-	PassTaint(s.outMap, inst.Name()+".2", inst.Iter.Name())
+	s.passTaint(inst.Name()+".1", inst.Iter.Name())
+	s.passTaint(inst.Name()+".2", inst.Iter.Name())
 }
 
 // CaseMapUpdate accepts a MapUpdate instruction
 func (s *TaintSwitcher) CaseMapUpdate(inst *ssa.MapUpdate) {
 	// pass taint in key and value
-	PassTaint(s.outMap, inst.Map.Name(), inst.Key.Name(), inst.Value.Name())
+	s.passTaint(inst.Map.Name(), inst.Key.Name(), inst.Value.Name())
 }
 
 // CasePhi accepts a Phi instruction
@@ -537,13 +546,13 @@ func (s *TaintSwitcher) CasePhi(inst *ssa.Phi) {
 	// Phi is the gather of instructions
 	// It may visit uninitialized register
 	for _, e := range inst.Edges {
-		PassTaint(s.outMap, inst.Name(), e.Name())
+		s.passTaint(inst.Name(), e.Name())
 	}
 }
 
 // CaseRange accepts a Range instruction
 func (s *TaintSwitcher) CaseRange(inst *ssa.Range) {
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	s.passTaint(inst.Name(), inst.X.Name())
 }
 
 // CaseReturn accepts a Return instruction
@@ -556,6 +565,7 @@ func (s *TaintSwitcher) CaseReturn(inst *ssa.Return) {
 			// merge receiver's taint into passthrough
 			passThrough.Recv.AddTaint(k)
 		}
+		s.returnFieldTaint("recv", 0, recv)
 	}
 	for i := 0; i < passThrough.ResultNum(); i++ {
 		result := inst.Results[i].Name()
@@ -564,6 +574,7 @@ func (s *TaintSwitcher) CaseReturn(inst *ssa.Return) {
 			// merge other results' taint
 			passThrough.Results[i].AddTaint(k)
 		}
+		s.returnFieldTaint("result", i, result)
 	}
 	for i := 0; i < s.taintAnalysis.passThrough.ParamNum(); i++ {
 		arg := passThrough.ParamName(i)
@@ -572,12 +583,13 @@ func (s *TaintSwitcher) CaseReturn(inst *ssa.Return) {
 			// merge args' taint
 			passThrough.Params[i].AddTaint(k)
 		}
+		s.returnFieldTaint("param", i, arg)
 	}
 }
 
 // CaseSend accepts a Send instruction
 func (s *TaintSwitcher) CaseSend(inst *ssa.Send) {
-	PassTaint(s.outMap, inst.Chan.Name(), inst.X.Name())
+	s.passTaint(inst.Chan.Name(), inst.X.Name())
 }
 
 // CaseSelect accepts a Select instruction
@@ -593,21 +605,23 @@ func (s *TaintSwitcher) CaseSelect(inst *ssa.Select) {
 
 // CaseSlice accepts a Slice instruction
 func (s *TaintSwitcher) CaseSlice(inst *ssa.Slice) {
-	PassTaint(s.outMap, inst.Name(), inst.X.Name())
+	s.passTaint(inst.Name(), inst.X.Name())
 }
 
 // CaseStore accepts a Store instruction
 func (s *TaintSwitcher) CaseStore(inst *ssa.Store) {
 	// Store needs to visit pointer
-	PassTaint(s.outMap, inst.Addr.Name(), inst.Val.Name())
+	s.passTaint(inst.Addr.Name(), inst.Val.Name())
 	if _, ok := (inst.Addr).(*ssa.Global); ok {
 		// save global anonymous function to initMap
 		if f, ok := (inst.Val).(*ssa.Function); ok {
-			(*s.taintAnalysis.config.InitMap)[inst.Addr.String()] = f
+			s.taintAnalysis.config.setInit(inst.Addr.String(), f)
 		}
 	}
 	// if inst.Addr points to struct or slice, update further
 	s.passPointTaint(inst.Addr)
+	// other pointers to the same memory see the stored taint too
+	s.storeAliases(inst.Addr)
 }
 
 // CaseTypeAssert accepts a TypeAssert instruction
@@ -615,10 +629,10 @@ func (s *TaintSwitcher) CaseTypeAssert(inst *ssa.TypeAssert) {
 	// we drop *ssa.Global, *ssa.FreeVar and *ssa.Const
 	if inst.CommaOk {
 		// if needs an ok, mark two variables, and the first one inherits taint
-		PassTaint(s.outMap, inst.Name()+".0", inst.X.Name())
+		s.passTaint(inst.Name()+".0", inst.X.Name())
 		GetTaintWrapper(s.outMap, inst.Name()+".1")
 	} else {
-		PassTaint(s.outMap, inst.Name(), inst.X.Name())
+		s.passTaint(inst.Name(), inst.X.Name())
 	}
 }
 
@@ -626,10 +640,44 @@ func (s *TaintSwitcher) CaseTypeAssert(inst *ssa.TypeAssert) {
 func (s *TaintSwitcher) CaseUnOp(inst *ssa.UnOp) {
 	if inst.Op == token.ARROW && inst.CommaOk {
 		// if needs an ok, mark two variables, and the first one inherits taint
-		PassTaint(s.outMap, inst.Name()+".0", inst.X.Name())
+		s.passTaint(inst.Name()+".0", inst.X.Name())
 		GetTaintWrapper(s.outMap, inst.Name()+".1")
 	} else {
-		PassTaint(s.outMap, inst.Name(), inst.X.Name())
+		s.passTaint(inst.Name(), inst.X.Name())
+	}
+	if inst.Op == token.MUL {
+		// a load sees the taint stored through other pointers to the same memory
+		s.loadAliases(inst.Name(), inst.X)
+	}
+}
+
+// CaseDeferredCall accepts a deferred call of an exceptional UnitGraph
+// the results of a deferred call are dropped, so only taint edges are collected
+func (s *TaintSwitcher) CaseDeferredCall(inst *graph.DeferredCall) {
+	c := s.taintAnalysis.config
+	if c.PassThroughOnly {
+		return
+	}
+	call := inst.Common()
+	if f := call.StaticCallee(); f != nil {
+		s.collectCallEdges(f, inst)
+		return
+	}
+	// try to use pointer analysis to select callee, the call site is the Defer
+	if c.UsePointerAnalysis {
+		if node := c.CallGraph.Nodes[inst.Parent()]; node != nil {
+			for _, edge := range node.Out {
+				if edge.Site == inst.Defer {
+					s.collectCallEdges(edge.Callee.Func, inst)
+					return
+				}
+			}
+		}
+	}
+	if call.IsInvoke() {
+		s.collectMethodEdges(call.Method, inst)
+	} else if signature, ok := call.Value.Type().Underlying().(*types.Signature); ok {
+		s.collectSignatureEdges(signature, inst)
 	}
 }
 
@@ -643,9 +691,12 @@ func (s *TaintSwitcher) passCallTaint(f *ssa.Function, inst *ssa.Call) {
 
 // passStaticCallTaint passes taint by a known *ssa.Function and a call
 func (s *TaintSwitcher) passStaticCallTaint(f *ssa.Function, inst *ssa.Call) {
-	container := s.taintAnalysis.config.PassThroughContainer
 	c := s.taintAnalysis.config
-	_, ok := (*container)[f.String()]
+	if s.passSanitizerTaint(f, inst) {
+		// a sanitizer's results carry the sanitized taint of its args, its body is not needed
+		return
+	}
+	_, ok := c.getPassThrough(f.String())
 	if !ok {
 		if needNull(f, c) {
 			// function is loaded from C file and has no body
@@ -663,64 +714,60 @@ func (s *TaintSwitcher) passStaticCallTaint(f *ssa.Function, inst *ssa.Call) {
 		Run(f, c)
 	}
 
-	passThroughCache := (*container)[f.String()]
+	passThroughCache, _ := c.getPassThrough(f.String())
+	args := make([]string, 0, len(inst.Call.Args))
+	for _, arg := range inst.Call.Args {
+		args = append(args, arg.Name())
+	}
+	var recv int
+	if passThroughCache.HasRecv() {
+		recv = 1
+	} else {
+		recv = 0
+	}
+	// for every parameter index in passthrough, collect arg's taint
+	// taints of fields are collected too, they are added after the positions are updated
 	var newRecvTaint *TaintWrapper
+	var newRecvFields map[string]*TaintWrapper
 	newResultTaints := make([]*TaintWrapper, 0)
+	newResultFields := make([]map[string]*TaintWrapper, 0)
 	newParamTaints := make([]*TaintWrapper, 0)
+	newParamFields := make([]map[string]*TaintWrapper, 0)
 	if passThroughCache.HasRecv() {
-		newTaint := NewTaintWrapper()
-		// for every parameter index in passthrough, collect arg's taint
-		for _, p := range passThroughCache.Recv {
-			newTaint.InheritTaint(s.outMap, inst.Call.Args[p].Name())
-		}
-		newRecvTaint = newTaint
+		newRecvTaint, newRecvFields = s.flowTaint(passThroughCache, "recv", 0, passThroughCache.Recv, args, args[0])
 	}
-	for _, result := range passThroughCache.Results {
-		newTaint := NewTaintWrapper()
-		// for every parameter index in passthrough, collect arg's taint
-		for _, p := range result {
-			newTaint.InheritTaint(s.outMap, inst.Call.Args[p].Name())
-		}
+	for i, result := range passThroughCache.Results {
+		newTaint, newFields := s.flowTaint(passThroughCache, "result", i, result, args, resultName(inst, i, passThroughCache.ResultNum()))
 		newResultTaints = append(newResultTaints, newTaint)
+		newResultFields = append(newResultFields, newFields)
 	}
-	for _, param := range passThroughCache.Params {
-		newTaint := NewTaintWrapper()
-		// for every parameter index in passthrough, collect arg's taint
-		for _, p := range param {
-			newTaint.InheritTaint(s.outMap, inst.Call.Args[p].Name())
-		}
+	for i, param := range passThroughCache.Params {
+		newTaint, newFields := s.flowTaint(passThroughCache, "param", i, param, args, args[recv+i])
 		newParamTaints = append(newParamTaints, newTaint)
+		newParamFields = append(newParamFields, newFields)
 	}
 	if passThroughCache.HasRecv() {
 		// update receiver's taint
 		// the receiver may be a pointer, so update further by the pointer
 		SetTaintWrapper(s.outMap, inst.Call.Args[0].Name(), newRecvTaint)
+		s.addTaints(newRecvFields)
 		if op, ok := (inst.Call.Args[0]).(*ssa.UnOp); ok {
-			PassTaint(s.outMap, op.X.Name(), op.Name())
+			s.passTaint(op.X.Name(), op.Name())
 			s.passPointTaint(op.X)
 		} else {
 			s.passPointTaint(inst.Call.Args[0])
 		}
 	}
 	for i := 0; i < passThroughCache.ResultNum(); i++ {
-		if passThroughCache.ResultNum() == 1 {
-			// if the function has one result
-			SetTaintWrapper(s.outMap, inst.Name(), newResultTaints[i])
-		} else {
-			// else mark the variables as "inst.Name().X"
-			// e.g. t0.1, t0.2
-			SetTaintWrapper(s.outMap, inst.Name()+"."+strconv.Itoa(i), newResultTaints[i])
-		}
+		// if the function has more than one result, the variables are marked as "inst.Name().X"
+		// e.g. t0.1, t0.2
+		SetTaintWrapper(s.outMap, resultName(inst, i, passThroughCache.ResultNum()), newResultTaints[i])
+		s.addTaints(newResultFields[i])
 	}
 	for i := 0; i < passThroughCache.ParamNum(); i++ {
-		var recv int
-		if passThroughCache.HasRecv() {
-			recv = 1
-		} else {
-			recv = 0
-		}
 		// update args' taint, use passPointTaint to pass back
 		SetTaintWrapper(s.outMap, inst.Call.Args[recv+i].Name(), newParamTaints[i])
+		s.addTaints(newParamFields[i])
 		s.passPointTaint(inst.Call.Args[recv+i])
 	}
 }
@@ -732,45 +779,50 @@ func (s *TaintSwitcher) passPointTaint(pointer ssa.Value) {
 		for _, _inst := range *addr.Referrers() {
 			switch inst := _inst.(type) {
 			case *ssa.Store:
-				PassTaint(s.outMap, inst.Val.Name(), addr.Name())
+				s.passTaint(inst.Val.Name(), addr.Name())
 				s.passBackCallTaint(inst.Val)
 			}
 		}
 	case *ssa.Convert:
 		// if addr is a *ssa.Convert, try use its addr.X to update further
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		s.passTaint(addr.X.Name(), addr.Name())
 		s.passPointTaint(addr.X)
 	case *ssa.TypeAssert:
 		// if addr is a *ssa.TypeAssert, try use its addr.X to update further
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		s.passTaint(addr.X.Name(), addr.Name())
 		s.passPointTaint(addr.X)
 	case *ssa.ChangeType:
 		// if addr is a *ssa.ChangeType, try use its addr.X to update further
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		s.passTaint(addr.X.Name(), addr.Name())
 		s.passPointTaint(addr.X)
 	case *ssa.ChangeInterface:
 		// if addr is a *ssa.ChangeInterface, try use its addr.X to update further
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		s.passTaint(addr.X.Name(), addr.Name())
 		s.passPointTaint(addr.X)
 	case *ssa.MakeInterface:
 		// if addr is a *ssa.MakeInterface, try use its addr.X to update further
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		s.passTaint(addr.X.Name(), addr.Name())
 		s.passPointTaint(addr.X)
 	case *ssa.UnOp:
 		// if addr is a *ssa.UnOp, try use its addr.X to update further
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		s.passTaint(addr.X.Name(), addr.Name())
 		s.passPointTaint(addr.X)
 	case *ssa.FieldAddr:
 		// if addr is still a *ssa.FieldAddr, update further
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		if field, ok := s.field(addr.X.Type(), addr.Field); ok {
+			// only the field of the struct gets the taint
+			s.writeField(addr.X.Name(), field, addr.Name())
+		} else {
+			s.passTaint(addr.X.Name(), addr.Name())
+		}
 		s.passPointTaint(addr.X)
 	case *ssa.IndexAddr:
 		// if addr is a *ssa.IndexAddr, update further
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		s.passTaint(addr.X.Name(), addr.Name())
 		s.passPointTaint(addr.X)
 	case *ssa.Slice:
 		// if addr is a *ssa.Slice, update underlying array
-		PassTaint(s.outMap, addr.X.Name(), addr.Name())
+		s.passTaint(addr.X.Name(), addr.Name())
 	}
 }
 
@@ -779,7 +831,7 @@ func (s *TaintSwitcher) passBackCallTaint(_call ssa.Value) {
 	if call, ok := _call.(*ssa.Call); ok {
 		for _, arg := range call.Call.Args {
 			if _, ok := arg.(*ssa.Parameter); ok {
-				PassTaint(s.outMap, arg.Name(), call.Name())
+				s.passTaint(arg.Name(), call.Name())
 			}
 		}
 	}
@@ -799,7 +851,7 @@ func (s *TaintSwitcher) passAppendTaint(inst *ssa.Call) {
 	SetTaintWrapper(s.outMap, inst.Name(), newTaint)
 	for i := 0; i < n; i++ {
 		// pass taint to every slice
-		PassTaint(s.outMap, inst.Call.Args[i].Name(), inst.Name())
+		s.passTaint(inst.Call.Args[i].Name(), inst.Name())
 	}
 }
 
@@ -821,9 +873,8 @@ func (s *TaintSwitcher) passInvokeTaint(f *types.Func, inst *ssa.Call) {
 
 // passMethodTaint passes taint by *ssa.Function and an invoke
 func (s *TaintSwitcher) passMethodTaint(f *ssa.Function, inst *ssa.Call) {
-	container := s.taintAnalysis.config.PassThroughContainer
 	c := s.taintAnalysis.config
-	_, ok := (*container)[f.String()]
+	_, ok := c.getPassThrough(f.String())
 	if !ok {
 		if needNull(f, c) {
 			// function is loaded from C file and has no body
@@ -837,79 +888,66 @@ func (s *TaintSwitcher) passMethodTaint(f *ssa.Function, inst *ssa.Call) {
 		Run(f, c)
 	}
 
-	passThroughCache := (*container)[f.String()]
+	passThroughCache, _ := c.getPassThrough(f.String())
+	// the first arg is inst.Call.Value, other args are in inst.Call.Args
+	args := []string{inst.Call.Value.Name()}
+	for _, arg := range inst.Call.Args {
+		args = append(args, arg.Name())
+	}
+	// for every parameter index in passthrough, collect arg's taint
+	// taints of fields are collected too, they are added after the positions are updated
 	var newRecvTaint *TaintWrapper
+	var newRecvFields map[string]*TaintWrapper
 	newResultTaints := make([]*TaintWrapper, 0)
+	newResultFields := make([]map[string]*TaintWrapper, 0)
 	newParamTaints := make([]*TaintWrapper, 0)
+	newParamFields := make([]map[string]*TaintWrapper, 0)
 	if passThroughCache.HasRecv() {
-		// for every parameter index in passthrough, collect arg's taint
-		for _, p := range passThroughCache.Recv {
-			newTaint := NewTaintWrapper()
-			if p == 0 {
-				// the first arg is inst.Call.Value
-				newTaint.InheritTaint(s.outMap, inst.Call.Value.Name())
-			} else {
-				// other args are in inst.Call.Args
-				newTaint.InheritTaint(s.outMap, inst.Call.Args[p-1].Name())
-			}
-			newRecvTaint = newTaint
-		}
+		newRecvTaint, newRecvFields = s.flowTaint(passThroughCache, "recv", 0, passThroughCache.Recv, args, args[0])
 	}
-	for _, result := range passThroughCache.Results {
-		newTaint := NewTaintWrapper()
-		// for every parameter index in passthrough, collect arg's taint
-		for _, p := range result {
-			if p == 0 {
-				// the first arg is inst.Call.Value
-				newTaint.InheritTaint(s.outMap, inst.Call.Value.Name())
-			} else {
-				// other args are in inst.Call.Args
-				newTaint.InheritTaint(s.outMap, inst.Call.Args[p-1].Name())
-			}
-		}
+	for i, result := range passThroughCache.Results {
+		newTaint, newFields := s.flowTaint(passThroughCache, "result", i, result, args, resultName(inst, i, passThroughCache.ResultNum()))
 		newResultTaints = append(newResultTaints, newTaint)
+		newResultFields = append(newResultFields, newFields)
 	}
-	for _, param := range passThroughCache.Params {
-		newTaint := NewTaintWrapper()
-		// for every parameter index in passthrough, collect arg's taint
-		for _, p := range param {
-			if p == 0 {
-				// the first arg is inst.Call.Value
-				newTaint.InheritTaint(s.outMap, inst.Call.Value.Name())
-			} else {
-				// other args are in inst.Call.Args
-				newTaint.InheritTaint(s.outMap, inst.Call.Args[p-1].Name())
-			}
-		}
+	for i, param := range passThroughCache.Params {
+		newTaint, newFields := s.flowTaint(passThroughCache, "param", i, param, args, inst.Call.Args[i].Name())
 		newParamTaints = append(newParamTaints, newTaint)
+		newParamFields = append(newParamFields, newFields)
 	}
 	if passThroughCache.HasRecv() {
 		// update receiver's taint
 		// the receiver may be a pointer, so update further by the pointer
 		SetTaintWrapper(s.outMap, inst.Call.Value.Name(), newRecvTaint)
+		s.addTaints(newRecvFields)
 		if op, ok := (inst.Call.Value).(*ssa.UnOp); ok {
-			PassTaint(s.outMap, op.X.Name(), op.Name())
+			s.passTaint(op.X.Name(), op.Name())
 			s.passPointTaint(op.X)
 		} else {
 			s.passPointTaint(inst.Call.Value)
 		}
 	}
 	for i := 0; i < passThroughCache.ResultNum(); i++ {
-		if passThroughCache.ResultNum() == 1 {
-			// if the function has one result
-			SetTaintWrapper(s.outMap, inst.Name(), newResultTaints[i])
-		} else {
-			// else mark the variables as "inst.Name().X"
-			// e.g. t0.1, t0.2
-			SetTaintWrapper(s.outMap, inst.Name()+"."+strconv.Itoa(i), newResultTaints[i])
-		}
+		// if the function has more than one result, the variables are marked as "inst.Name().X"
+		// e.g. t0.1, t0.2
+		SetTaintWrapper(s.outMap, resultName(inst, i, passThroughCache.ResultNum()), newResultTaints[i])
+		s.addTaints(newResultFields[i])
 	}
 	for i := 0; i < passThroughCache.ParamNum(); i++ {
 		// update args' taint
 		SetTaintWrapper(s.outMap, inst.Call.Args[i].Name(), newParamTaints[i])
+		s.addTaints(newParamFields[i])
 	}
 }
 
+// resultName returns the name of the i'th of n results of a call
+func resultName(inst *ssa.Call, i int, n int) string {
+	if n == 1 {
+		return inst.Name()
+	}
+	return inst.Name() + "." + strconv.Itoa(i)
+}
+
 // passNullTaint passes taint when we can't know a declared function's body or have to inhibit recursive
 // actually no taint will be passed
 // note that this may lose some taint but help analysis keep working
@@ -973,104 +1011,78 @@ func (s *TaintSwitcher) passAnonymousTaint(signature *types.Signature, inst *ssa
 
 // passCopyTaint pass taint by copy
 func (s *TaintSwitcher) passCopyTaint(inst *ssa.Call) {
-	PassTaint(s.outMap, inst.Call.Args[0].Name(), inst.Call.Args[1].Name())
+	s.passTaint(inst.Call.Args[0].Name(), inst.Call.Args[1].Name())
 	GetTaintWrapper(s.outMap, inst.Name())
 }
 
-func (s *TaintSwitcher) collectCallEdges(f *ssa.Function, inst *ssa.Call) {
-	taintGraph := s.taintAnalysis.config.TaintGraph
+func (s *TaintSwitcher) collectCallEdges(f *ssa.Function, inst ssa.CallInstruction) {
+	c := s.taintAnalysis.config
+	taintGraph := c.TaintGraph
 	if s.taintAnalysis.Graph.Func.Name() == "init" {
 		return
 	}
-	for i, arg := range inst.Call.Args {
-		for name := range *GetTaint(s.outMap, arg.Name()) {
-			for k, v := range s.taintAnalysis.Graph.Func.Params {
-				if v.Name() == name {
-					edge := Edge{From: s.taintAnalysis.Graph.Func.String(), FromIndex: k, To: f.String(), ToIndex: i}
-					key := s.taintAnalysis.Graph.Func.String() + "#" + strconv.Itoa(k)
-					key2 := f.String() + "#" + strconv.Itoa(i)
-					node := (*taintGraph.Nodes)[key]
-					node2 := (*taintGraph.Nodes)[key2]
-					if node.IsIntra {
-						if _, ok := (*taintGraph.Edges)[key+"#"+key2]; ok {
-							continue
-						} else {
-							(*taintGraph.Edges)[key+"#"+key2] = &edge
-						}
-						node.Out = append(node.Out, &edge)
-						node2.In = append(node2.In, &edge)
-						passProperty(node2, &edge)
-					}
-				}
+	position, instruction, call := s.callSite(inst)
+	for i, arg := range inst.Common().Args {
+		wrapper := s.wholeTaint(arg.Name())
+		node2 := &Node{Function: f, Canonical: f.String(), Index: i, Position: paramPosition(f, i), IsStatic: true}
+		for k, v := range s.taintAnalysis.Graph.Func.Params {
+			kinds, ok := wrapper.SanitizedKinds(v.Name())
+			if !ok {
+				continue
+			}
+			edge := Edge{From: s.taintAnalysis.Graph.Func.String(), FromIndex: k, To: f.String(), ToIndex: i, Sanitized: kinds,
+				Position: position, Instruction: instruction, Call: call}
+			key := s.taintAnalysis.Graph.Func.String() + "#" + strconv.Itoa(k)
+			key2 := f.String() + "#" + strconv.Itoa(i)
+			node := (*taintGraph.Nodes)[key]
+			if node.IsIntra && !sanitizedSink(c.Ruler, node2, kinds) {
+				c.addEdge(key, key2, &edge, nil)
 			}
 		}
 	}
 }
 
 // collectMethodsEdges records node only use type information
-func (s *TaintSwitcher) collectMethodEdges(f *types.Func, inst *ssa.Call) {
+func (s *TaintSwitcher) collectMethodEdges(f *types.Func, inst ssa.CallInstruction) {
 	signature, ok := f.Type().(*types.Signature)
-	ruler := s.taintAnalysis.config.Ruler
-	taintGraph := s.taintAnalysis.config.TaintGraph
+	c := s.taintAnalysis.config
+	taintGraph := c.TaintGraph
+	position, instruction, call := s.callSite(inst)
 	if ok {
-		for name := range *GetTaint(s.outMap, inst.Call.Value.Name()) {
-			// contruct taint edge from receiver to arg
-			for k, v := range s.taintAnalysis.Graph.Func.Params {
-				if v.Name() == name {
-					edge := Edge{From: s.taintAnalysis.Graph.Func.String(), FromIndex: k, To: f.String(), ToIndex: 0}
-					key := s.taintAnalysis.Graph.Func.String() + "#" + strconv.Itoa(k)
-					key2 := f.String() + "#" + strconv.Itoa(0)
-					node := (*taintGraph.Nodes)[key]
-					if node.IsIntra {
-						node.Out = append(node.Out, &edge)
-						if _, ok := (*taintGraph.Edges)[key+"#"+key2]; ok {
-							continue
-						} else {
-							(*taintGraph.Edges)[key+"#"+key2] = &edge
-						}
-						if node2, ok := (*taintGraph.Nodes)[key2]; ok {
-							node2.In = append(node2.In, &edge)
-							passProperty(node2, &edge)
-						} else {
-							node2 := &Node{Canonical: signature.String(), Index: 0, Out: make([]*Edge, 0), In: make([]*Edge, 0), IsSignature: false, IsMethod: true, IsStatic: false}
-							decidePropertry(node2, ruler)
-							node2.In = append(node2.In, &edge)
-							(*taintGraph.Nodes)[f.String()] = node2
-							passProperty(node2, &edge)
-						}
-					}
-				}
+		node2 := &Node{Canonical: signature.String(), Index: 0, Position: s.taintAnalysis.Graph.Func.Prog.Fset.Position(f.Pos()),
+			IsSignature: false, IsMethod: true, IsStatic: false}
+		// contruct taint edge from receiver to arg
+		wrapper := s.wholeTaint(inst.Common().Value.Name())
+		for k, v := range s.taintAnalysis.Graph.Func.Params {
+			kinds, ok := wrapper.SanitizedKinds(v.Name())
+			if !ok {
+				continue
+			}
+			edge := Edge{From: s.taintAnalysis.Graph.Func.String(), FromIndex: k, To: f.String(), ToIndex: 0, Sanitized: kinds,
+				Position: position, Instruction: instruction, Call: call}
+			key := s.taintAnalysis.Graph.Func.String() + "#" + strconv.Itoa(k)
+			key2 := f.String() + "#" + strconv.Itoa(0)
+			node := (*taintGraph.Nodes)[key]
+			if node.IsIntra && !sanitizedSink(c.Ruler, node2, kinds) {
+				c.addEdge(key, key2, &edge, node2)
 			}
 		}
 		n := signature.Params().Len()
 		for i := 0; i < n; i++ {
 			// contruct taint edge from param to arg
-			for name := range *GetTaint(s.outMap, inst.Call.Args[i].Name()) {
-				for k, v := range s.taintAnalysis.Graph.Func.Params {
-					if v.Name() == name {
-						edge := Edge{From: s.taintAnalysis.Graph.Func.String(), FromIndex: k, To: f.String(), ToIndex: i + 1}
-						key := s.taintAnalysis.Graph.Func.String() + "#" + strconv.Itoa(k)
-						key2 := f.String() + "#" + strconv.Itoa(0)
-						node := (*taintGraph.Nodes)[key]
-						if node.IsIntra {
-							node.Out = append(node.Out, &edge)
-							if _, ok := (*taintGraph.Edges)[key+"#"+key2]; ok {
-								continue
-							} else {
-								(*taintGraph.Edges)[key+"#"+key2] = &edge
-							}
-							if node2, ok := (*taintGraph.Nodes)[key2]; ok {
-								node2.In = append(node2.In, &edge)
-								passProperty(node2, &edge)
-							} else {
-								node2 := &Node{Canonical: signature.String(), Index: 0, Out: make([]*Edge, 0), In: make([]*Edge, 0), IsSignature: false, IsMethod: true, IsStatic: false}
-								decidePropertry(node2, ruler)
-								node2.In = append(node2.In, &edge)
-								(*taintGraph.Nodes)[f.String()] = node2
-								passProperty(node2, &edge)
-							}
-						}
-					}
+			wrapper := s.wholeTaint(inst.Common().Args[i].Name())
+			for k, v := range s.taintAnalysis.Graph.Func.Params {
+				kinds, ok := wrapper.SanitizedKinds(v.Name())
+				if !ok {
+					continue
+				}
+				edge := Edge{From: s.taintAnalysis.Graph.Func.String(), FromIndex: k, To: f.String(), ToIndex: i + 1, Sanitized: kinds,
+					Position: position, Instruction: instruction, Call: call}
+				key := s.taintAnalysis.Graph.Func.String() + "#" + strconv.Itoa(k)
+				key2 := f.String() + "#" + strconv.Itoa(0)
+				node := (*taintGraph.Nodes)[key]
+				if node.IsIntra && !sanitizedSink(c.Ruler, node2, kinds) {
+					c.addEdge(key, key2, &edge, node2)
 				}
 			}
 		}
@@ -1078,38 +1090,67 @@ func (s *TaintSwitcher) collectMethodEdges(f *types.Func, inst *ssa.Call) {
 }
 
 // collectSignatureEdges records node only use signature information
-func (s *TaintSwitcher) collectSignatureEdges(signature *types.Signature, inst *ssa.Call) {
-	ruler := s.taintAnalysis.config.Ruler
-	taintGraph := s.taintAnalysis.config.TaintGraph
+func (s *TaintSwitcher) collectSignatureEdges(signature *types.Signature, inst ssa.CallInstruction) {
+	c := s.taintAnalysis.config
+	taintGraph := c.TaintGraph
+	position, instruction, call := s.callSite(inst)
+	// a signature has no declaration, the node is at the declaration of the called value
+	nodePosition := position
+	if pos := inst.Common().Value.Pos(); pos.IsValid() {
+		nodePosition = s.taintAnalysis.Graph.Func.Prog.Fset.Position(pos)
+	}
+	node2 := &Node{Canonical: signature.String(), Index: 0, Position: nodePosition, IsSignature: true, IsMethod: false, IsStatic: false}
 	n := signature.Params().Len()
 	for i := 0; i < n; i++ {
-		for name := range *GetTaint(s.outMap, inst.Call.Args[i].Name()) {
-			for k, v := range s.taintAnalysis.Graph.Func.Params {
-				if v.Name() == name {
-					edge := Edge{From: s.taintAnalysis.Graph.Func.String(), FromIndex: k, To: signature.String(), ToIndex: i}
-					key := s.taintAnalysis.Graph.Func.String() + "#" + strconv.Itoa(k)
-					key2 := signature.String() + "#" + strconv.Itoa(0)
-					node := (*taintGraph.Nodes)[key]
-					if node.IsIntra {
-						node.Out = append(node.Out, &edge)
-						if _, ok := (*taintGraph.Edges)[key+"#"+key2]; ok {
-							continue
-						} else {
-							(*taintGraph.Edges)[key+"#"+key2] = &edge
-						}
-						if node2, ok := (*taintGraph.Nodes)[key2]; ok {
-							node2.In = append(node2.In, &edge)
-							passProperty(node2, &edge)
-						} else {
-							node2 := &Node{Canonical: signature.String(), Index: 0, Out: make([]*Edge, 0), In: make([]*Edge, 0), IsSignature: true, IsMethod: false, IsStatic: false}
-							decidePropertry(node2, ruler)
-							node2.In = append(node2.In, &edge)
-							(*taintGraph.Nodes)[signature.String()] = node2
-							passProperty(node2, &edge)
-						}
-					}
-				}
+		wrapper := s.wholeTaint(inst.Common().Args[i].Name())
+		for k, v := range s.taintAnalysis.Graph.Func.Params {
+			kinds, ok := wrapper.SanitizedKinds(v.Name())
+			if !ok {
+				continue
+			}
+			edge := Edge{From: s.taintAnalysis.Graph.Func.String(), FromIndex: k, To: signature.String(), ToIndex: i, Sanitized: kinds,
+				Position: position, Instruction: instruction, Call: call}
+			key := s.taintAnalysis.Graph.Func.String() + "#" + strconv.Itoa(k)
+			key2 := signature.String() + "#" + strconv.Itoa(0)
+			node := (*taintGraph.Nodes)[key]
+			if node.IsIntra && !sanitizedSink(c.Ruler, node2, kinds) {
+				c.addEdge(key, key2, &edge, node2)
 			}
 		}
 	}
 }
+
+// callSite returns the position, the SSA and the ordinal of a call instruction among the calls of its callee
+// in the function, the SSA is like the output of ssa.WriteFunction
+func (s *TaintSwitcher) callSite(inst ssa.CallInstruction) (token.Position, string, int) {
+	f := s.taintAnalysis.Graph.Func
+	position := f.Prog.Fset.Position(inst.Pos())
+	call := 0
+	callee := calleeName(inst.Common())
+blocks:
+	for _, b := range f.Blocks {
+		for _, other := range b.Instrs {
+			if other == inst {
+				break blocks
+			}
+			if c, ok := other.(ssa.CallInstruction); ok && calleeName(c.Common()) == callee {
+				call++
+			}
+		}
+	}
+	if v := inst.Value(); v != nil {
+		return position, v.Name() + " = " + v.String(), call
+	}
+	return position, inst.String(), call
+}
+
+// calleeName returns the static callee of a call, its method if it is an invoke or else its signature
+func calleeName(common *ssa.CallCommon) string {
+	if common.IsInvoke() {
+		return common.Method.FullName()
+	}
+	if f := common.StaticCallee(); f != nil {
+		return f.String()
+	}
+	return common.Signature().String()
+}
@@ -1,5 +1,11 @@
 package taint
 
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
 // TaintWrapper represents a wrapper of taint
 type TaintWrapper struct {
 	innerTaint *map[string]bool
@@ -76,3 +82,115 @@ func MergeTaintWrapper(inout *map[any]any, in *map[any]any, name string) {
 	wrapper := GetTaintWrapper(inout, name)
 	wrapper.InheritTaint(in, name)
 }
+
+// sanitizedSep separates the name of a taint from the kinds it is sanitized for, e.g. query|sqli,xss
+// names of parameters never contain it
+const sanitizedSep = "|"
+
+// splitTaint returns the name of a taint and the kinds it is sanitized for
+func splitTaint(taint string) (string, []string) {
+	name, kinds, ok := strings.Cut(taint, sanitizedSep)
+	if !ok {
+		return name, nil
+	}
+	return name, strings.Split(kinds, ",")
+}
+
+// joinTaint returns a taint of name sanitized for kinds
+func joinTaint(name string, kinds []string) string {
+	if len(kinds) == 0 {
+		return name
+	}
+	return name + sanitizedSep + strings.Join(kinds, ",")
+}
+
+// Sanitize returns a new wrapper with the taints of w sanitized for kinds, nil kinds clear all taints
+func (w *TaintWrapper) Sanitize(kinds []string) *TaintWrapper {
+	newTaint := NewTaintWrapper()
+	if kinds == nil {
+		return newTaint
+	}
+	for taint := range *w.innerTaint {
+		name, old := splitTaint(taint)
+		newTaint.AddTaint(joinTaint(name, unionKinds(old, kinds)))
+	}
+	return newTaint
+}
+
+// SanitizedKinds returns whether w has taint of name or of its fields, and the kinds all of them are sanitized for
+// a taint of name which is not sanitized on some path makes the kinds empty
+func (w *TaintWrapper) SanitizedKinds(name string) ([]string, bool) {
+	var kinds []string
+	found := false
+	for taint := range *w.innerTaint {
+		n, k := splitTaint(taint)
+		if base, _ := splitPath(n); base != name {
+			continue
+		}
+		if !found {
+			kinds = k
+			found = true
+		} else {
+			kinds = intersectKinds(kinds, k)
+		}
+	}
+	return kinds, found
+}
+
+// PathKinds returns the access paths of name w has taint of, "" for name itself,
+// with the kinds all taints of each path are sanitized for
+func (w *TaintWrapper) PathKinds(name string) map[string][]string {
+	paths := make(map[string][]string)
+	for taint := range *w.innerTaint {
+		n, k := splitTaint(taint)
+		base, path := splitPath(n)
+		if base != name {
+			continue
+		}
+		if kinds, ok := paths[path]; ok {
+			paths[path] = intersectKinds(kinds, k)
+		} else {
+			paths[path] = k
+		}
+	}
+	return paths
+}
+
+// InheritSanitized inherits taints from a wrapper with key, sanitized for kinds
+func (w *TaintWrapper) InheritSanitized(flow *map[any]any, name string, kinds []string) {
+	if len(kinds) == 0 {
+		w.InheritTaint(flow, name)
+		return
+	}
+	for taint := range *GetTaintWrapper(flow, name).Sanitize(kinds).innerTaint {
+		(*w.innerTaint)[taint] = true
+	}
+}
+
+// unionKinds returns the sorted union of kinds
+func unionKinds(a []string, b []string) []string {
+	set := make(map[string]bool)
+	for _, kind := range a {
+		set[kind] = true
+	}
+	for _, kind := range b {
+		set[kind] = true
+	}
+	kinds := make([]string, 0, len(set))
+	for kind := range set {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// intersectKinds returns the kinds in both a and b, in the order of a
+func intersectKinds(a []string, b []string) []string {
+	kinds := make([]string, 0)
+	for _, kind := range a {
+		if slices.Contains(b, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
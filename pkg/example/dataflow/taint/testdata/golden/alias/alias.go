@@ -0,0 +1,47 @@
+// Package alias has writes through one pointer and reads through another pointer to the same memory
+package alias
+
+import "example.com/golden/lib"
+
+type box struct {
+	x string
+	y string
+}
+
+var global *string
+
+//go:noinline
+func id(p *string) *string { return p }
+
+// ViaCall writes through a pointer returned by a call
+func ViaCall(req *lib.Req) {
+	p := new(string)
+	q := id(p)
+	*q = req.Q
+	lib.Query(*p)
+}
+
+// ViaGlobal writes through a global holding the pointer
+func ViaGlobal(req *lib.Req) {
+	p := new(string)
+	global = p
+	*global = req.Q
+	lib.Query(*p)
+}
+
+// ViaField writes through a pointer to a field, only that field is tainted with field-sensitive taint
+func ViaField(req *lib.Req) {
+	b := &box{}
+	p := id(&b.x)
+	*p = req.Q
+	lib.Exec(b.x)
+	lib.Render(b.y)
+}
+
+// Unrelated writes through a pointer which does not alias the read one
+func Unrelated(req *lib.Req) {
+	p := new(string)
+	q := id(new(string))
+	*q = req.Q
+	lib.Query(*p)
+}
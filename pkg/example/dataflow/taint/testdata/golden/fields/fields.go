@@ -0,0 +1,43 @@
+// Package fields has flows through fields of structs, which only field-sensitive taint tells apart
+package fields
+
+import "example.com/golden/lib"
+
+type box struct {
+	x string
+	y string
+}
+
+type outer struct {
+	in struct {
+		deep struct {
+			x string
+			y string
+		}
+	}
+}
+
+func setX(b *box, s string) { b.x = s }
+
+// Local taints x only, y reaches Query and x reaches Exec
+func Local(req *lib.Req) {
+	b := &box{}
+	b.x = req.Q
+	lib.Query(b.y)
+	lib.Exec(b.x)
+}
+
+// Callee taints x in a callee, its passthrough records the field
+func Callee(req *lib.Req) {
+	b := &box{}
+	setX(b, req.Q)
+	lib.Query(b.y)
+	lib.Exec(b.x)
+}
+
+// Deep writes a path longer than the k-limit, which is cut and taints its siblings
+func Deep(req *lib.Req) {
+	o := &outer{}
+	o.in.deep.x = req.Q
+	lib.Query(o.in.deep.y)
+}
@@ -0,0 +1,15 @@
+// Package findings has sinks reached through several call sites and calls
+package findings
+
+import "example.com/golden/lib"
+
+func run(s string) { lib.Exec(s) }
+
+// Handler calls the sink at two call sites and through run
+func Handler(req *lib.Req) {
+	lib.Query(req.Q)
+	if req.Q != "" {
+		lib.Query(req.Q + "!")
+	}
+	run(req.Q)
+}
@@ -0,0 +1,29 @@
+// Package lib has the sources, sinks and sanitizers of the golden tests, it imports nothing
+package lib
+
+// Req is the parameter type of sources
+type Req struct {
+	Q    string
+	Body *Body
+}
+
+// Body is a field of Req
+type Body struct {
+	X string
+	Y string
+}
+
+// Query is a sqli sink
+func Query(s string) {}
+
+// Render is a xss sink
+func Render(s string) {}
+
+// Exec is a sink of all kinds
+func Exec(s string) {}
+
+// QuoteSQL sanitizes sqli
+func QuoteSQL(s string) string { return s }
+
+// IsSafe sanitizes all kinds when it returns true
+func IsSafe(s string) bool { return s == "" }
@@ -0,0 +1,51 @@
+// Package parallel has call chains, recursion and dynamic calls, so its functions are analyzed in several waves
+package parallel
+
+import "example.com/golden/lib"
+
+type runner interface {
+	run(s string)
+}
+
+type queryRunner struct{}
+
+func (queryRunner) run(s string) { lib.Query(s) }
+
+type execRunner struct{}
+
+func (execRunner) run(s string) { lib.Exec(s) }
+
+func wrap(s string) string { return "(" + s + ")" }
+
+func twice(s string) string { return wrap(wrap(s)) }
+
+func even(s string, n int) string {
+	if n == 0 {
+		return s
+	}
+	return odd(s+"e", n-1)
+}
+
+func odd(s string, n int) string {
+	if n == 0 {
+		return ""
+	}
+	return even(s+"o", n-1)
+}
+
+func dispatch(r runner, s string) { r.run(s) }
+
+func Handler(req *lib.Req) {
+	q := twice(req.Q)
+	lib.Query(q)
+	dispatch(queryRunner{}, even(q, 4))
+	dispatch(execRunner{}, odd(req.Q, 3))
+	f := func(s string) { lib.Render(s) }
+	f(q)
+}
+
+func Safe(req *lib.Req) {
+	lib.Query(wrap("const"))
+}
+
+func Apply(fn func(string), req *lib.Req) { fn(req.Q) }
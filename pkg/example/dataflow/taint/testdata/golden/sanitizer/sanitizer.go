@@ -0,0 +1,44 @@
+// Package sanitizer has flows through sanitizers of some kinds and of all kinds
+package sanitizer
+
+import "example.com/golden/lib"
+
+// quote wraps a sanitizer, its passthrough records the sanitized flow
+func quote(s string) string { return lib.QuoteSQL(s) }
+
+// QuotedQuery is sanitized for the only kind of the sink, it is not a finding
+func QuotedQuery(req *lib.Req) {
+	lib.Query(lib.QuoteSQL(req.Q))
+}
+
+// QuotedRender is sanitized for sqli, the xss sink still reports it
+func QuotedRender(req *lib.Req) {
+	lib.Render(lib.QuoteSQL(req.Q))
+}
+
+// QuotedExec reaches a sink of all kinds, sanitizing sqli does not clear it
+func QuotedExec(req *lib.Req) {
+	lib.Exec(lib.QuoteSQL(req.Q))
+}
+
+// WrappedQuery is sanitized inside a callee
+func WrappedQuery(req *lib.Req) {
+	lib.Query(quote(req.Q))
+}
+
+// CheckedExec is sanitized on the branch where the check holds
+func CheckedExec(req *lib.Req) {
+	q := req.Q
+	if lib.IsSafe(q) {
+		lib.Exec(q)
+	}
+}
+
+// UncheckedExec reaches the sink on the branch where the check fails
+func UncheckedExec(req *lib.Req) {
+	q := req.Q
+	if lib.IsSafe(q) {
+		return
+	}
+	lib.Exec(q)
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"lib"
+	"passthru"
+)
+
+// Handler gets the taint of Pass from its fact, and no taint from Drop
+func Handler(req *lib.Req) { // want Handler:"passthrough"
+	lib.Exec(passthru.Pass(req.Q)) // want "taint from position 0 of handler.Handler reaches position 0 of sink lib.Exec"
+	lib.Exec(passthru.Drop(req.Q))
+}
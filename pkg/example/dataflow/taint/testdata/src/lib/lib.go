@@ -0,0 +1,5 @@
+package lib
+
+type Req struct{ Q string }
+
+func Exec(s string) {}
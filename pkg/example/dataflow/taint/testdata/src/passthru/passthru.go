@@ -0,0 +1,15 @@
+package passthru
+
+import "lib"
+
+func Pass(s string) string { // want Pass:"passthrough .*\\[\\[0\\]\\]"
+	return s
+}
+
+func Drop(s string) string { // want Drop:"passthrough"
+	return ""
+}
+
+func Run(s string) { // want Run:"passthrough"
+	lib.Exec(s) // want "taint from position 0 of passthru.Run reaches position 0 of sink lib.Exec"
+}